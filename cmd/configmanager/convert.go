@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/configManager"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// runConvert reads a configuration file with the loader of one format and writes it with the writer of
+// another. Structured formats keep their nesting and value types; flat formats are nested again by
+// splitting keys such as PARENT_CHILD on '_'
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("convert", "[input]", stderr)
+	from := flagSet.String("from", "", "input format, e.g. env, json or yaml (default: the extension of input)")
+	to := flagSet.String("to", "", "output format (default: the extension of -o)")
+	output := flagSet.String("o", "", "file to write to (default: stdout)")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() > 1 {
+		flagSet.Usage()
+		return 2
+	}
+
+	input := flagSet.Arg(0)
+	if input == "-" {
+		input = ""
+	}
+	fromName, err := formatFile(*from, input, "-from")
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager convert: %v\n", err)
+		return 2
+	}
+	toName, err := formatFile(*to, *output, "-to")
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager convert: %v\n", err)
+		return 2
+	}
+
+	var content []byte
+	if input == "" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(input)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager convert: %v\n", err)
+		return 1
+	}
+
+	root, err := readTree(fromName, input, content)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager convert: %s\n", describeError(input, err))
+		return 1
+	}
+
+	writer, err := configManager.WriterFactory(toName)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager convert: %v\n", err)
+		return 2
+	}
+	var converted bytes.Buffer
+	if err := writer.Write(&converted, root); err != nil {
+		fmt.Fprintf(stderr, "configmanager convert: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(converted.Bytes())
+	} else {
+		err = os.WriteFile(*output, converted.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager convert: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// formatFile returns a file name that selects the loader or writer of a format: the file itself if no
+// format is given, and a file with the format as extension otherwise
+func formatFile(format, file, flagName string) (string, error) {
+	if format != "" {
+		return "config." + strings.TrimPrefix(strings.ToLower(format), "."), nil
+	}
+	if file == "" || filepath.Ext(file) == "" && !strings.HasPrefix(filepath.Base(file), ".") {
+		return "", fmt.Errorf("%s is required when the format cannot be told from a file extension", flagName)
+	}
+	return file, nil
+}
+
+// readTree parses content with the loader for fileName. Loaders that only produce flat keys have their
+// nesting rebuilt from the keys
+func readTree(fileName, input string, content []byte) (*tree.Node, error) {
+	loader, err := configManager.LoaderFactory(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if treeLoader, ok := loader.(configManager.TreeLoader); ok {
+		return treeLoader.LoadTree(bytes.NewReader(content), input)
+	}
+
+	readerLoader, ok := loader.(configManager.ReaderLoader)
+	if !ok {
+		return nil, fmt.Errorf("loader %T cannot read from a stream", loader)
+	}
+	configs, err := readerLoader.LoadReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return internal.Unflatten(configs, "_"), nil
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/configManager"
+	"github.com/LetsFocus/configManager/pkg/crypt"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// keyFlags select the encryption key of a command, like WithDecryptionKeyFile and WithDecryptionKeyEnv
+type keyFlags struct {
+	file string
+	env  string
+}
+
+func (f *keyFlags) register(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&f.file, "key-file", "", "file holding the encryption key")
+	flagSet.StringVar(&f.env, "key-env", crypt.DefaultKeyEnv, "environment variable holding the key, or naming its file with a _FILE suffix")
+}
+
+func (f *keyFlags) load() (crypt.Key, error) {
+	if f.file != "" {
+		return crypt.ReadKeyFile(f.file)
+	}
+	key, err := crypt.KeyFromEnv(f.env)
+	if err == crypt.ErrNoKey {
+		return nil, fmt.Errorf("%v, use -key-file or set %s", err, f.env)
+	}
+	return key, err
+}
+
+// runEncrypt encrypts the values of the given keys of a file in place
+func runEncrypt(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("encrypt", "FILE KEY...", stderr)
+	var keyFlags keyFlags
+	keyFlags.register(flagSet)
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() < 2 {
+		flagSet.Usage()
+		return 2
+	}
+
+	key, err := keyFlags.load()
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager encrypt: %v\n", err)
+		return 2
+	}
+	return editValues("encrypt", flagSet.Arg(0), flagSet.Args()[1:], stdout, stderr, func(name string, value scalar) (scalar, error) {
+		if crypt.IsEncrypted(value.text) {
+			return value, nil
+		}
+		encrypted, err := crypt.Encrypt(key, name, value.text, value.valueType)
+		return scalar{text: encrypted}, err
+	})
+}
+
+// runDecrypt decrypts the values of the given keys of a file in place, or every encrypted value
+func runDecrypt(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("decrypt", "FILE [KEY...]", stderr)
+	var keyFlags keyFlags
+	keyFlags.register(flagSet)
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() < 1 {
+		flagSet.Usage()
+		return 2
+	}
+
+	key, err := keyFlags.load()
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager decrypt: %v\n", err)
+		return 2
+	}
+	return editValues("decrypt", flagSet.Arg(0), flagSet.Args()[1:], stdout, stderr, func(name string, value scalar) (scalar, error) {
+		if !crypt.IsEncrypted(value.text) {
+			return value, nil
+		}
+		plaintext, valueType, err := crypt.Decrypt(key, name, value.text)
+		return scalar{text: plaintext, valueType: valueType}, err
+	})
+}
+
+// runRotate encrypts the encrypted values of a file again with a new key
+func runRotate(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("rotate", "FILE [KEY...]", stderr)
+	var keyFlags keyFlags
+	keyFlags.register(flagSet)
+	newKeyFile := flagSet.String("new-key-file", "", "file holding the new encryption key (required)")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() < 1 || *newKeyFile == "" {
+		flagSet.Usage()
+		return 2
+	}
+
+	key, err := keyFlags.load()
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager rotate: %v\n", err)
+		return 2
+	}
+	newKey, err := crypt.ReadKeyFile(*newKeyFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager rotate: %v\n", err)
+		return 2
+	}
+	return editValues("rotate", flagSet.Arg(0), flagSet.Args()[1:], stdout, stderr, func(name string, value scalar) (scalar, error) {
+		if !crypt.IsEncrypted(value.text) {
+			return scalar{}, fmt.Errorf("%s is not encrypted", name)
+		}
+		plaintext, valueType, err := crypt.Decrypt(key, name, value.text)
+		if err != nil {
+			return scalar{}, err
+		}
+		encrypted, err := crypt.Encrypt(newKey, name, plaintext, valueType)
+		return scalar{text: encrypted}, err
+	})
+}
+
+// scalar is the text of a value and the type it has in the file, one of the crypt types
+type scalar struct {
+	text      string
+	valueType string
+}
+
+// span is a scalar value of a configuration file and the bytes it occupies
+type span struct {
+	key        string
+	value      scalar
+	start, end int
+}
+
+// editValues rewrites the values of the given keys of a file with edit, or of every encrypted value if no
+// keys are given, leaving the rest of the file untouched. The result is parsed again to make sure every
+// edited key holds its new value before the file is written
+func editValues(name, file string, names []string, stdout, stderr io.Writer, edit func(key string, value scalar) (scalar, error)) int {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager %s: %v\n", name, err)
+		return 1
+	}
+	content, edited, err := editContent(file, content, names, edit)
+	if err == nil && len(edited) > 0 {
+		err = writeFile(file, content)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager %s: %v\n", name, err)
+		return 1
+	}
+
+	if len(edited) == 0 {
+		fmt.Fprintf(stdout, "%s: nothing to %s\n", file, name)
+	}
+	for _, key := range edited {
+		fmt.Fprintf(stdout, "%s: %s %s\n", file, pastTense[name], key)
+	}
+	return 0
+}
+
+// pastTense reports what the encryption commands did to a key
+var pastTense = map[string]string{"encrypt": "encrypted", "decrypt": "decrypted", "rotate": "rotated"}
+
+// editContent applies edit to the spans of content and returns the new content with the edited keys
+func editContent(file string, content []byte, names []string, edit func(key string, value scalar) (scalar, error)) ([]byte, []string, error) {
+	spans, err := valueSpans(file, content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var targets []span
+	if len(names) == 0 {
+		for _, s := range spans {
+			if crypt.IsEncrypted(s.value.text) {
+				targets = append(targets, s)
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		s, found := spans[strings.ToUpper(name)]
+		if !found {
+			return nil, nil, fmt.Errorf("%s: no value for key %s", file, name)
+		}
+		if !seen[s.key] {
+			seen[s.key] = true
+			targets = append(targets, s)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].start > targets[j].start })
+
+	expected := make(map[string]scalar)
+	var edited []string
+	for _, target := range targets {
+		value, err := edit(target.key, target.value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", target.key, err)
+		}
+		if value == target.value {
+			continue
+		}
+		text, err := renderValue(file, value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", target.key, err)
+		}
+		content = bytes.Join([][]byte{content[:target.start], []byte(text), content[target.end:]}, nil)
+		expected[strings.ToUpper(target.key)] = value
+		edited = append(edited, target.key)
+	}
+
+	spans, err = valueSpans(file, content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot edit %s in place: %v", file, err)
+	}
+	for key, value := range expected {
+		if spans[key].value != value {
+			return nil, nil, fmt.Errorf("cannot edit %s in place: %s would not hold the new value", file, key)
+		}
+	}
+
+	sort.Strings(edited)
+	return content, edited, nil
+}
+
+// valueSpans finds the scalar values of a .env, JSON or YAML file, by uppercased flat key. Values that come
+// from included files are left out
+func valueSpans(file string, content []byte) (map[string]span, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".env":
+		return envSpans(content), nil
+	case ".json":
+		return treeSpans(file, content, jsonExtent, false)
+	case ".yaml", ".yml":
+		return treeSpans(file, content, yamlExtent, true)
+	default:
+		return nil, fmt.Errorf("unsupported file type %s, expected .env, .json or .yaml", file)
+	}
+}
+
+// envSpans finds the values of KEY=value lines
+func envSpans(content []byte) map[string]span {
+	spans := make(map[string]span)
+	offset := 0
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		start := offset
+		offset += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		equals := strings.Index(line, "=")
+		if equals < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:equals])
+		value := line[equals+1:]
+		valueStart := start + equals + 1 + len(value) - len(strings.TrimLeft(value, " \t"))
+		value = strings.TrimSpace(value)
+		spans[strings.ToUpper(key)] = span{key: key, value: scalar{text: value}, start: valueStart, end: valueStart + len(value)}
+	}
+	return spans
+}
+
+// treeSpans finds the scalars of a structured file from the positions of its tree. extent returns the end
+// of the scalar that starts at an offset; columns count runes if runeColumns is set and bytes otherwise
+func treeSpans(file string, content []byte, extent func(content []byte, start int) (int, error), runeColumns bool) (map[string]span, error) {
+	loader, err := configManager.LoaderFactory(file)
+	if err != nil {
+		return nil, err
+	}
+	treeLoader, ok := loader.(configManager.TreeLoader)
+	if !ok {
+		return nil, fmt.Errorf("loader %T does not keep positions", loader)
+	}
+	root, err := treeLoader.LoadTree(bytes.NewReader(content), file)
+	if err != nil {
+		return nil, err
+	}
+	_, index, err := internal.FlattenTree(root, keys.Upper)
+	if err != nil {
+		return nil, err
+	}
+
+	lineStarts := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	spans := make(map[string]span)
+	for key, node := range index {
+		if node.Kind != tree.ScalarNode || node.Pos.File != file || node.Pos.Line == 0 || node.Pos.Line > len(lineStarts) {
+			continue
+		}
+		start := lineStarts[node.Pos.Line-1]
+		if runeColumns {
+			for column := 1; column < node.Pos.Column && start < len(content); column++ {
+				_, size := utf8.DecodeRune(content[start:])
+				start += size
+			}
+		} else {
+			start += node.Pos.Column - 1
+		}
+		end, err := extent(content, start)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", node.Pos, err)
+		}
+		spans[key] = span{key: key, value: scalar{text: node.String(), valueType: scalarType(node.Value)}, start: start, end: end}
+	}
+	return spans, nil
+}
+
+// jsonExtent returns the end of the JSON string or literal that starts at start
+func jsonExtent(content []byte, start int) (int, error) {
+	if start >= len(content) {
+		return 0, errors.New("value out of range")
+	}
+	if content[start] == '"' {
+		return quotedExtent(content, start)
+	}
+	end := start
+	for end < len(content) && !strings.ContainsRune(",}] \t\r\n/", rune(content[end])) {
+		end++
+	}
+	return end, nil
+}
+
+// yamlExtent returns the end of the quoted or plain YAML scalar that starts at start. Block scalars and
+// scalars with anchors or tags are not supported
+func yamlExtent(content []byte, start int) (int, error) {
+	if start >= len(content) {
+		return 0, errors.New("value out of range")
+	}
+	switch content[start] {
+	case '"':
+		return quotedExtent(content, start)
+	case '\'':
+		for end := start + 1; end < len(content); end++ {
+			if content[end] == '\'' {
+				if end+1 < len(content) && content[end+1] == '\'' {
+					end++
+					continue
+				}
+				return end + 1, nil
+			}
+		}
+		return 0, errors.New("unterminated string")
+	case '|', '>':
+		return 0, errors.New("block scalars cannot be edited in place")
+	case '&', '!', '*':
+		return 0, errors.New("values with anchors, aliases or tags cannot be edited in place")
+	}
+
+	end := start
+	for end < len(content) && content[end] != '\n' && content[end] != '\r' {
+		if content[end] == '#' && end > start && (content[end-1] == ' ' || content[end-1] == '\t') {
+			break
+		}
+		end++
+	}
+	for end > start && (content[end-1] == ' ' || content[end-1] == '\t') {
+		end--
+	}
+	return end, nil
+}
+
+// quotedExtent returns the end of a double-quoted string with backslash escapes
+func quotedExtent(content []byte, start int) (int, error) {
+	for end := start + 1; end < len(content); end++ {
+		switch content[end] {
+		case '\\':
+			end++
+		case '"':
+			return end + 1, nil
+		}
+	}
+	return 0, errors.New("unterminated string")
+}
+
+// renderValue formats a value for the file: as is in .env files and for the numbers, booleans and
+// timestamps of JSON and YAML files, and as a double-quoted string, which JSON and YAML read alike,
+// otherwise
+func renderValue(file string, value scalar) (string, error) {
+	if strings.ToLower(filepath.Ext(file)) == ".env" {
+		if strings.ContainsAny(value.text, "\r\n") || strings.TrimSpace(value.text) != value.text {
+			return "", errors.New("value cannot be written to a .env file")
+		}
+		return value.text, nil
+	}
+	if value.valueType != crypt.TypeString {
+		return value.text, nil
+	}
+
+	var quoted bytes.Buffer
+	encoder := json.NewEncoder(&quoted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value.text); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(quoted.String(), "\n"), nil
+}
+
+// scalarType returns the crypt type of a decoded scalar
+func scalarType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return crypt.TypeBoolean
+	case int, int64, uint64:
+		return crypt.TypeInteger
+	case float64:
+		return crypt.TypeNumber
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return crypt.TypeNumber
+		}
+		return crypt.TypeInteger
+	case time.Time:
+		return crypt.TypeTimestamp
+	}
+	return crypt.TypeString
+}
+
+// writeFile replaces the content of a file, keeping its permissions
+func writeFile(file string, content []byte) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, info.Mode().Perm())
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/configManager"
+)
+
+// change is a key whose value differs between the two sides of a diff
+type change struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// difference lists the keys added, removed and changed from one configuration to another
+type difference struct {
+	Added   map[string]string `json:"added"`
+	Removed map[string]string `json:"removed"`
+	Changed map[string]change `json:"changed"`
+}
+
+func (d difference) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// runDiff compares the merged configuration of two profiles, directories or files. It exits with 1 if
+// they differ and 2 on errors, like diff(1)
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("diff", "FROM TO", stderr)
+	var load loadFlags
+	load.register(flagSet)
+	format := flagSet.String("format", "text", "output format: text (unified) or json")
+	showSecrets := flagSet.Bool("show-secrets", false, "print the values of secret keys")
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: configmanager diff [flags] FROM TO")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "FROM and TO are configuration files, directories to discover files in, or")
+		fmt.Fprintln(stderr, "comma-separated profiles loaded from -base-path.")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Flags:")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() != 2 {
+		flagSet.Usage()
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "configmanager diff: unknown format %q\n", *format)
+		return 2
+	}
+
+	from, err := load.resolve(flagSet.Arg(0), stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager diff: %s: %v\n", flagSet.Arg(0), err)
+		return 2
+	}
+	to, err := load.resolve(flagSet.Arg(1), stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager diff: %s: %v\n", flagSet.Arg(1), err)
+		return 2
+	}
+
+	diff := compare(from, to, *showSecrets)
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diff); err != nil {
+			fmt.Fprintf(stderr, "configmanager diff: %v\n", err)
+			return 2
+		}
+	} else if !diff.empty() {
+		printUnified(stdout, flagSet.Arg(0), flagSet.Arg(1), diff)
+	}
+
+	if diff.empty() {
+		return 0
+	}
+	return 1
+}
+
+// resolve loads the configuration named by arg: a single file, a directory discovered like LoadConfigs,
+// or comma-separated profiles loaded from the base path. Every profile must have a file in the base path,
+// so that a misspelled profile is not mistaken for one without differences
+func (f loadFlags) resolve(arg string, stderr io.Writer) (map[string]string, error) {
+	info, err := os.Stat(arg)
+	switch {
+	case err == nil && !info.IsDir():
+		loader, err := configManager.LoaderFactory(arg)
+		if err != nil {
+			return nil, err
+		}
+		return loader.Load(arg)
+	case err == nil:
+		f.basePath = arg
+	default:
+		for _, profile := range strings.Split(arg, ",") {
+			profile = strings.TrimSpace(profile)
+			if profile == "" {
+				continue
+			}
+			found, err := hasProfileFile(f.basePath, profile)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return nil, fmt.Errorf("not a file or directory, and there is no %s.<ext> file for the profile in %s", profile, f.basePath)
+			}
+		}
+		f.env = arg
+	}
+
+	// set the profiles of this side explicitly, so that those of the other side do not leak into it
+	previous, wasSet := os.LookupEnv("APP_ENV")
+	defer func() {
+		if wasSet {
+			os.Setenv("APP_ENV", previous)
+		} else {
+			os.Unsetenv("APP_ENV")
+		}
+	}()
+	if f.env == "" {
+		os.Unsetenv("APP_ENV")
+	} else {
+		os.Setenv("APP_ENV", f.env)
+	}
+
+	cm, err := f.load(stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for key, origin := range cm.Provenance() {
+		if origin.Null {
+			continue
+		}
+		values[key] = origin.Value
+	}
+	return values, nil
+}
+
+// hasProfileFile reports whether basePath holds a file named after profile with the extension of a
+// registered loader
+func hasProfileFile(basePath, profile string) (bool, error) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name != profile+filepath.Ext(name) {
+			continue
+		}
+		if _, err := configManager.LoaderFactory(filepath.Join(basePath, name)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// compare lists the differences from one configuration to another, redacting secret values
+func compare(from, to map[string]string, showSecrets bool) difference {
+	diff := difference{Added: map[string]string{}, Removed: map[string]string{}, Changed: map[string]change{}}
+	for key, value := range from {
+		toValue, found := to[key]
+		switch {
+		case !found:
+			diff.Removed[key] = redact(key, value, showSecrets)
+		case toValue != value:
+			diff.Changed[key] = change{From: redact(key, value, showSecrets), To: redact(key, toValue, showSecrets)}
+		}
+	}
+	for key, value := range to {
+		if _, found := from[key]; !found {
+			diff.Added[key] = redact(key, value, showSecrets)
+		}
+	}
+	return diff
+}
+
+// printUnified prints the differences as a unified diff of KEY=value lines, sorted by key
+func printUnified(w io.Writer, fromName, toName string, diff difference) {
+	keys := make([]string, 0, len(diff.Added)+len(diff.Removed)+len(diff.Changed))
+	for key := range diff.Added {
+		keys = append(keys, key)
+	}
+	for key := range diff.Removed {
+		keys = append(keys, key)
+	}
+	for key := range diff.Changed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", fromName, toName)
+	for _, key := range keys {
+		if value, removed := diff.Removed[key]; removed {
+			fmt.Fprintf(w, "-%s=%s\n", key, value)
+		} else if value, added := diff.Added[key]; added {
+			fmt.Fprintf(w, "+%s=%s\n", key, value)
+		} else {
+			fmt.Fprintf(w, "-%s=%s\n+%s=%s\n", key, diff.Changed[key].From, key, diff.Changed[key].To)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// runDump prints the effective value of every loaded key, hiding secrets unless asked not to
+func runDump(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("dump", "", stderr)
+	var load loadFlags
+	load.register(flagSet)
+	format := flagSet.String("format", "text", "output format: text (KEY=value lines) or json")
+	showSecrets := flagSet.Bool("show-secrets", false, "print the values of secret keys")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "configmanager dump: unknown format %q\n", *format)
+		return 2
+	}
+
+	cm, err := load.load(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager dump: %v\n", err)
+		return 1
+	}
+
+	provenance := cm.Provenance()
+	values := make(map[string]interface{}, len(provenance))
+	keys := make([]string, 0, len(provenance))
+	for key, origin := range provenance {
+		if origin.Null {
+			values[key] = nil
+		} else {
+			values[key] = redact(key, origin.Value, *showSecrets)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(values); err != nil {
+			fmt.Fprintf(stderr, "configmanager dump: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, key := range keys {
+		if values[key] == nil {
+			// keys set to an explicit null have no value, so they are listed as comments
+			fmt.Fprintf(stdout, "# %s is null\n", key)
+		} else {
+			fmt.Fprintf(stdout, "%s=%s\n", key, values[key])
+		}
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/LetsFocus/configManager/pkg/configManager"
+)
+
+// runExplain prints every layer that supplied a value for a key, from the lowest precedence to the one in
+// effect
+func runExplain(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("explain", "KEY", stderr)
+	var load loadFlags
+	load.register(flagSet)
+	showSecrets := flagSet.Bool("show-secrets", false, "print the values of secret keys")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return 2
+	}
+	key := flagSet.Arg(0)
+
+	cm, err := load.load(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager explain: %v\n", err)
+		return 1
+	}
+
+	history := cm.History(key)
+	if len(history) == 0 {
+		fmt.Fprintf(stdout, "%s is not defined by any layer\n", key)
+		return 1
+	}
+
+	// explicit nulls supply no value, so the last layer that is not a null is in effect
+	inEffect := len(history) - 1
+	for inEffect > 0 && history[inEffect].Null {
+		inEffect--
+	}
+	if history[inEffect].Null {
+		inEffect = len(history) - 1
+	}
+
+	value := func(origin configManager.Origin) string {
+		if origin.Null {
+			return "null"
+		}
+		return redact(key, origin.Value, *showSecrets)
+	}
+	fmt.Fprintf(stdout, "%s = %s\n", key, value(history[inEffect]))
+	for i, origin := range history {
+		marker := "overridden"
+		switch {
+		case i == inEffect:
+			marker = "effective"
+		case i > inEffect:
+			marker = "ignored"
+		}
+		fmt.Fprintf(stdout, "  %d. %s: %s (%s)\n", i+1, origin, value(origin), marker)
+	}
+	return 0
+}
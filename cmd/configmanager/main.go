@@ -0,0 +1,132 @@
+// Command configmanager inspects configuration files with the discovery rules and loaders of the
+// configManager package, so that they can be checked in CI or on call without writing Go.
+//
+// Usage:
+//
+//	configmanager validate [-base-path dir] [-schema file] [path ...]
+//	configmanager dump [-base-path dir] [-env profiles] [-format text|json] [-show-secrets]
+//	configmanager explain [-base-path dir] [-env profiles] [-show-secrets] KEY
+//	configmanager diff [-base-path dir] [-format text|json] [-show-secrets] FROM TO
+//	configmanager convert [-from format] [-to format] [-o output] [input]
+//	configmanager schema [-o output] PACKAGE.TYPE
+//	configmanager encrypt [-key-file file] FILE KEY...
+//	configmanager decrypt [-key-file file] FILE [KEY...]
+//	configmanager rotate [-key-file file] -new-key-file file FILE [KEY...]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/configManager"
+)
+
+// command is a subcommand of configmanager
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "validate", summary: "parse every configuration file and report syntax errors", run: runValidate},
+		{name: "dump", summary: "print the merged configuration for a set of profiles", run: runDump},
+		{name: "explain", summary: "show every layer that defines a key", run: runExplain},
+		{name: "diff", summary: "compare the configuration of two profiles, directories or files", run: runDiff},
+		{name: "convert", summary: "convert a configuration file to another format", run: runConvert},
+		{name: "schema", summary: "write the JSON Schema of a configuration struct", run: runSchema},
+		{name: "encrypt", summary: "encrypt values of a .env, JSON or YAML file in place", run: runEncrypt},
+		{name: "decrypt", summary: "decrypt values of a .env, JSON or YAML file in place", run: runDecrypt},
+		{name: "rotate", summary: "encrypt the encrypted values of a file again with a new key", run: runRotate},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the subcommand named by args[0] and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		return 2
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "configmanager: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: configmanager <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "configmanager <command> -h" for the flags of a command.`)
+}
+
+// loadFlags are the flags shared by the commands that load the merged configuration
+type loadFlags struct {
+	basePath string
+	env      string
+	confD    bool
+	verbose  bool
+}
+
+func (f *loadFlags) register(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&f.basePath, "base-path", "./configs", "directory to discover configuration files in")
+	flagSet.StringVar(&f.env, "env", os.Getenv("APP_ENV"), "comma-separated profiles to load, as in APP_ENV")
+	flagSet.BoolVar(&f.confD, "conf-d", false, "also load drop-in files from <base-path>/conf.d")
+	flagSet.BoolVar(&f.verbose, "v", false, "report every loaded file")
+}
+
+// load builds the merged configuration. Files that fail to load are reported on stderr
+func (f *loadFlags) load(stderr io.Writer) (*configManager.Config, error) {
+	if f.env != "" {
+		os.Setenv("APP_ENV", f.env)
+	}
+
+	var log bytes.Buffer
+	opts := []configManager.Option{configManager.WithBasePath(f.basePath), configManager.WithLogOutput(&log)}
+	if f.confD {
+		opts = append(opts, configManager.WithConfD())
+	}
+	cm := configManager.New(opts...)
+
+	// Load again to get errors that New only reports
+	log.Reset()
+	err := cm.LoadConfigs(f.basePath)
+	for _, line := range strings.SplitAfter(log.String(), "\n") {
+		if f.verbose || strings.HasPrefix(line, "Error") {
+			io.WriteString(stderr, line)
+		}
+	}
+	return cm, err
+}
+
+// newFlagSet creates the flag set of a command, printing usage and errors to stderr
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	flagSet := flag.NewFlagSet("configmanager "+name, flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: configmanager %s [flags] %s\n\nFlags:\n", name, args)
+		flagSet.PrintDefaults()
+	}
+	return flagSet
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/configManager"
+	"github.com/LetsFocus/configManager/pkg/crypt"
+	"github.com/stretchr/testify/assert"
+)
+
+// runCommand runs configmanager with args and returns its exit code and output
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// writeConfigs creates a configuration directory with the given files
+func writeConfigs(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	return dir
+}
+
+// unsetenv removes the variables exported by a load when the test ends
+func unsetenv(t *testing.T, keys ...string) {
+	t.Cleanup(func() {
+		for _, key := range append(keys, "APP_ENV") {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runCommand()
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "validate")
+
+	code, _, stderr = runCommand("frobnicate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "frobnicate"`)
+}
+
+func TestValidate(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		".yaml":        "cli_test:\n  name: base\n",
+		"prod.json":    "{\n  \"name\": ,\n}\n",
+		"broken.ini":   "[section\n",
+		"notes.txt":    "not a configuration file",
+		"conf.d/a.env": "A=1\n",
+	})
+
+	code, stdout, _ := runCommand("validate", "-base-path", dir)
+	assert.Equal(t, 1, code, "validate should fail when a file does not parse")
+	assert.Contains(t, stdout, "FAIL "+filepath.Join(dir, "prod.json")+":2:11: ", "JSON errors should carry their position")
+	assert.Contains(t, stdout, "FAIL "+filepath.Join(dir, "broken.ini")+": line 1: ", "INI errors should carry their line")
+	assert.Contains(t, stdout, "4 files validated, 2 with errors")
+
+	code, stdout, _ = runCommand("validate", filepath.Join(dir, ".yaml"), filepath.Join(dir, "conf.d"))
+	assert.Equal(t, 0, code, "validate should succeed for valid files")
+	assert.Contains(t, stdout, "2 files validated, 0 with errors")
+
+	schema := filepath.Join(dir, "schema.json")
+	os.WriteFile(schema, []byte(`{"properties": {"cli_test": {"required": ["name", "port"]}}}`), 0644)
+	code, stdout, _ = runCommand("validate", "-schema", schema, filepath.Join(dir, ".yaml"), filepath.Join(dir, "conf.d"))
+	assert.Equal(t, 1, code, "validate should fail when a file breaks the schema")
+	assert.Contains(t, stdout, "FAIL "+filepath.Join(dir, ".yaml")+":2:3: /cli_test: missing required property \"port\"")
+	assert.Contains(t, stdout, "2 files validated, 1 with errors", "files that cannot be validated against a schema should only be parsed")
+}
+
+func TestDump(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		".yaml":     "cli_dump:\n  name: base\n  password: hunter2\n  timeout: ~\n",
+		"prod.yaml": "cli_dump:\n  name: prod\n",
+	})
+	unsetenv(t, "CLI_DUMP_NAME", "CLI_DUMP_PASSWORD")
+
+	code, stdout, _ := runCommand("dump", "-base-path", dir, "-env", "prod")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "CLI_DUMP_NAME=prod\nCLI_DUMP_PASSWORD=[REDACTED]\n# CLI_DUMP_TIMEOUT is null\n", stdout, "dump should print the merged configuration with secrets redacted")
+
+	code, stdout, _ = runCommand("dump", "-base-path", dir, "-env", "local", "-format", "json", "-show-secrets")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"CLI_DUMP_NAME": "base", "CLI_DUMP_PASSWORD": "hunter2", "CLI_DUMP_TIMEOUT": null}`, stdout)
+}
+
+func TestExplain(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		".env":           "CLI_EXPLAIN_PORT=80\n",
+		"prod.env":       "CLI_EXPLAIN_PORT=8080\n",
+		"conf.d/z.env":   "CLI_EXPLAIN_PORT=9090\n",
+		"conf.d/zz.yaml": "cli_explain:\n  port: ~\n  debug: ~\n",
+	})
+	unsetenv(t, "CLI_EXPLAIN_PORT")
+
+	code, stdout, _ := runCommand("explain", "-base-path", dir, "-env", "prod", "-conf-d", "CLI_EXPLAIN_PORT")
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Equal(t, []string{
+		"CLI_EXPLAIN_PORT = 9090",
+		"  1. file (" + filepath.Join(dir, ".env") + "): 80 (overridden)",
+		"  2. file (" + filepath.Join(dir, "prod.env") + ", profile prod): 8080 (overridden)",
+		"  3. file (" + filepath.Join(dir, "conf.d", "z.env") + "): 9090 (effective)",
+		"  4. file (" + filepath.Join(dir, "conf.d", "zz.yaml") + "): null (ignored)",
+	}, lines, "a null should not replace the value of a lower layer")
+
+	code, stdout, _ = runCommand("explain", "-base-path", dir, "-conf-d", "CLI_EXPLAIN_DEBUG")
+	assert.Equal(t, 0, code, "keys set to null should be explained")
+	assert.Equal(t, "CLI_EXPLAIN_DEBUG = null\n  1. file ("+filepath.Join(dir, "conf.d", "zz.yaml")+"): null (effective)\n", stdout)
+
+	code, stdout, _ = runCommand("explain", "-base-path", dir, "CLI_EXPLAIN_MISSING")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "CLI_EXPLAIN_MISSING is not defined by any layer\n", stdout)
+}
+
+func TestDiff(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		".yaml":          "cli_diff:\n  host: localhost\n  port: 80\n",
+		"staging.yaml":   "cli_diff:\n  host: staging-db\n  debug: true\n  token: abc\n",
+		"prod.env":       "CLI_DIFF_HOST=prod-db\nCLI_DIFF_REPLICAS=3\nCLI_DIFF_TOKEN=xyz\n",
+		"other/app.json": `{"cli_diff": {"host": "staging-db", "port": 80, "debug": true, "token": "abc"}}`,
+	})
+	unsetenv(t, "CLI_DIFF_HOST", "CLI_DIFF_PORT", "CLI_DIFF_DEBUG", "CLI_DIFF_TOKEN", "CLI_DIFF_REPLICAS")
+
+	code, stdout, _ := runCommand("diff", "-base-path", dir, "staging", "prod")
+	assert.Equal(t, 1, code, "diff should exit with 1 when the configurations differ")
+	assert.Equal(t, ""+
+		"--- staging\n"+
+		"+++ prod\n"+
+		"-CLI_DIFF_DEBUG=true\n"+
+		"-CLI_DIFF_HOST=staging-db\n"+
+		"+CLI_DIFF_HOST=prod-db\n"+
+		"+CLI_DIFF_REPLICAS=3\n"+
+		"-CLI_DIFF_TOKEN=[REDACTED]\n"+
+		"+CLI_DIFF_TOKEN=[REDACTED]\n", stdout)
+
+	code, stdout, _ = runCommand("diff", "-base-path", dir, "-format", "json", "staging", "prod")
+	assert.Equal(t, 1, code)
+	assert.JSONEq(t, `{
+		"added": {"CLI_DIFF_REPLICAS": "3"},
+		"removed": {"CLI_DIFF_DEBUG": "true"},
+		"changed": {
+			"CLI_DIFF_HOST": {"from": "staging-db", "to": "prod-db"},
+			"CLI_DIFF_TOKEN": {"from": "[REDACTED]", "to": "[REDACTED]"}
+		}
+	}`, stdout)
+
+	code, stdout, _ = runCommand("diff", "-base-path", dir, "staging", filepath.Join(dir, "other", "app.json"))
+	assert.Equal(t, 0, code, "diff should exit with 0 when the configurations are equal")
+	assert.Equal(t, "", stdout)
+
+	code, _, _ = runCommand("diff", "-base-path", dir, "staging")
+	assert.Equal(t, 2, code, "diff should exit with 2 on usage errors")
+
+	code, stdout, stderr := runCommand("diff", "-base-path", dir, "staging", "prdo")
+	assert.Equal(t, 2, code, "diff should exit with 2 for a profile without files")
+	assert.Equal(t, "", stdout)
+	assert.Contains(t, stderr, "prdo: not a file or directory, and there is no prdo.<ext> file for the profile in "+dir)
+
+	code, _, _ = runCommand("diff", "-base-path", dir, "staging", "staging,prdo")
+	assert.Equal(t, 2, code, "every profile of a list should have a file")
+}
+
+func TestDiff_ProfileAndDirectory(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"prod.env":        "CLI_DIFF_SIDE=prod\n",
+		"other/local.env": "CLI_DIFF_SIDE=otherlocal\n",
+		"other/prod.env":  "CLI_DIFF_SIDE=otherprod\n",
+	})
+	unsetenv(t, "CLI_DIFF_SIDE")
+	os.Unsetenv("APP_ENV")
+	other := filepath.Join(dir, "other")
+
+	code, stdout, stderr := runCommand("diff", "-base-path", dir, "prod", other)
+	assert.Equal(t, 1, code, stderr)
+	assert.Equal(t, "--- prod\n+++ "+other+"\n-CLI_DIFF_SIDE=prod\n+CLI_DIFF_SIDE=otherlocal\n", stdout, "the directory should be loaded with the default profile")
+
+	code, stdout, stderr = runCommand("diff", "-base-path", dir, other, "prod")
+	assert.Equal(t, 1, code, stderr)
+	assert.Equal(t, "--- "+other+"\n+++ prod\n-CLI_DIFF_SIDE=otherlocal\n+CLI_DIFF_SIDE=prod\n", stdout, "the order of the arguments should not matter")
+	_, set := os.LookupEnv("APP_ENV")
+	assert.False(t, set, "APP_ENV should be restored")
+}
+
+func TestConvert(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"app.env":  "DB_HOST=localhost\nDB_PORT=5432\nHOSTS=a,b\nDEBUG=true\n",
+		"app.json": `{"db": {"host": "localhost", "port": 5432}}`,
+	})
+
+	code, stdout, _ := runCommand("convert", "-to", "yaml", filepath.Join(dir, "app.env"))
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "db:\n  host: localhost\n  port: 5432\ndebug: true\nhosts: a,b\n", stdout, "nesting should be rebuilt from PARENT_CHILD keys")
+
+	output := filepath.Join(dir, "out.properties")
+	code, _, _ = runCommand("convert", "-o", output, filepath.Join(dir, "app.json"))
+	assert.Equal(t, 0, code)
+	content, _ := os.ReadFile(output)
+	assert.Equal(t, "db.host = localhost\ndb.port = 5432\n", string(content), "the output format should follow the extension of -o")
+
+	code, _, stderr := runCommand("convert", filepath.Join(dir, "app.json"))
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "-to is required")
+
+	code, _, stderr = runCommand("convert", "-to", "toml", filepath.Join(dir, "app.json"))
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "unsupported file type")
+}
+
+func TestSchema(t *testing.T) {
+	code, _, stderr := runCommand("schema", "./configs")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `"./configs" does not name a type`)
+
+	if testing.Short() {
+		t.Skip("schema builds a program with go run")
+	}
+
+	output := filepath.Join(t.TempDir(), "schema.json")
+	code, _, stderr = runCommand("schema", "-o", output, "../../pkg/tree.Position")
+	assert.Equal(t, 0, code, stderr)
+	content, _ := os.ReadFile(output)
+	assert.Contains(t, string(content), `"$schema": "https://json-schema.org/draft/2020-12/schema"`)
+	assert.Contains(t, string(content), `"line": {`, "the schema should describe the fields of the type")
+
+	code, _, _ = runCommand("schema", "../../pkg/tree.Missing")
+	assert.Equal(t, 1, code, "unknown types should fail to build")
+}
+
+func TestEncryptDecryptRotate(t *testing.T) {
+	key, _ := crypt.GenerateKey()
+	newKey, _ := crypt.GenerateKey()
+	dir := writeConfigs(t, map[string]string{
+		"key":      key.String() + "\n",
+		"new-key":  newKey.String() + "\n",
+		".env":     "# database\nDB_USER=app\nDB_PASSWORD = s3cret\nDB_PORT=5432\nDB_HOST=localhost\n",
+		"app.json": "{\n  \"db\": {\"password\": \"s3cret\", \"port\": 5432},\n  \"name\": \"app\"\n}\n",
+		"app.yaml": "db:\n  password: 's3cret'   # rotate quarterly\n  port: 5432\nname: app\n",
+	})
+	keyFile, newKeyFile := filepath.Join(dir, "key"), filepath.Join(dir, "new-key")
+
+	for _, name := range []string{".env", "app.json", "app.yaml"} {
+		file := filepath.Join(dir, name)
+		original, _ := os.ReadFile(file)
+
+		code, stdout, stderr := runCommand("encrypt", "-key-file", keyFile, file, "db_password", "db_port")
+		assert.Equal(t, 0, code, stderr)
+		assert.Equal(t, file+": encrypted DB_PASSWORD\n"+file+": encrypted DB_PORT\n", stdout)
+		encrypted, _ := os.ReadFile(file)
+		assert.NotContains(t, string(encrypted), "s3cret", "the value should be encrypted in %s", name)
+		assert.NotContains(t, string(encrypted), "5432", "the number should be encrypted in %s", name)
+		assert.Contains(t, string(encrypted), "ENC[aes256-gcm,")
+
+		loader, _ := configManager.LoaderFactory(file)
+		configs, err := loader.Load(file)
+		assert.NoError(t, err, "the edited file should still parse")
+		plaintext, _, err := crypt.Decrypt(key, "DB_PASSWORD", configs["DB_PASSWORD"])
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cret", plaintext)
+		_, _, err = crypt.Decrypt(key, "DB_PORT", configs["DB_PASSWORD"])
+		assert.Error(t, err, "envelopes should not decrypt under another key")
+
+		code, stdout, stderr = runCommand("rotate", "-key-file", keyFile, "-new-key-file", newKeyFile, file)
+		assert.Equal(t, 0, code, stderr)
+		assert.Equal(t, file+": rotated DB_PASSWORD\n"+file+": rotated DB_PORT\n", stdout)
+
+		code, _, stderr = runCommand("decrypt", "-key-file", keyFile, file)
+		assert.Equal(t, 1, code, "decrypting with the old key should fail after a rotation")
+		assert.Contains(t, stderr, "wrong key")
+
+		code, stdout, stderr = runCommand("decrypt", "-key-file", newKeyFile, file)
+		assert.Equal(t, 0, code, stderr)
+		assert.Equal(t, file+": decrypted DB_PASSWORD\n"+file+": decrypted DB_PORT\n", stdout)
+		decrypted, _ := os.ReadFile(file)
+		if name == ".env" {
+			assert.Equal(t, string(original), string(decrypted), "the rest of %s should be preserved, numbers unquoted", name)
+		} else {
+			assert.Equal(t, strings.Replace(string(original), "'s3cret'", `"s3cret"`, 1), string(decrypted), "the rest of %s should be preserved", name)
+		}
+	}
+
+	code, _, stderr := runCommand("encrypt", "-key-file", keyFile, filepath.Join(dir, "app.yaml"), "db_missing")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no value for key db_missing")
+
+	code, _, stderr = runCommand("encrypt", "-key-env", "CLI_TEST_NO_KEY", filepath.Join(dir, "app.yaml"), "name")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "no encryption key is configured, use -key-file or set CLI_TEST_NO_KEY")
+}
@@ -0,0 +1,28 @@
+package main
+
+import "strings"
+
+// redacted replaces the values of secret keys in output
+const redacted = "[REDACTED]"
+
+// secretKeyParts are the parts of a key name that mark its value as secret
+var secretKeyParts = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "CREDENTIAL", "PRIVATE_KEY", "API_KEY", "ACCESS_KEY"}
+
+// isSecret reports whether the name of key suggests that its value is secret
+func isSecret(key string) bool {
+	key = strings.ToUpper(key)
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact hides the value of a secret key unless show is set. Empty values are left as they are
+func redact(key, value string, show bool) string {
+	if show || value == "" || !isSecret(key) {
+		return value
+	}
+	return redacted
+}
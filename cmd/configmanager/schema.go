@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// schemaProgram prints the JSON Schema of a type, it is built inside the package that declares the type
+var schemaProgram = template.Must(template.New("main").Parse(`// Code generated by configmanager schema. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/LetsFocus/configManager/pkg/configManager"
+	target {{printf "%q" .ImportPath}}
+)
+
+func main() {
+	schema, err := configManager.GenerateJSONSchema(&target.{{.Type}}{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(schema)
+}
+`))
+
+// runSchema writes the JSON Schema of a configuration struct. The struct is named as PACKAGE.TYPE, where
+// PACKAGE is an import path or a directory, and must be exported. Since the command cannot load Go types
+// itself, it builds a small program in a temporary directory inside the package and runs it with go run,
+// so the package has to belong to a module that requires configManager
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("schema", "PACKAGE.TYPE", stderr)
+	output := flagSet.String("o", "", "file to write to (default: stdout)")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return 2
+	}
+
+	pkg, typeName, err := splitType(flagSet.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager schema: %v\n", err)
+		return 2
+	}
+
+	schema, err := generateSchema(pkg, typeName, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager schema: %v\n", err)
+		return 1
+	}
+	schema = append(schema, '\n')
+
+	if *output == "" {
+		_, err = stdout.Write(schema)
+	} else {
+		err = os.WriteFile(*output, schema, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "configmanager schema: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// splitType splits PACKAGE.TYPE at the last '.' that follows the last '/'
+func splitType(arg string) (string, string, error) {
+	slash := strings.LastIndex(arg, "/")
+	dot := strings.LastIndex(arg[slash+1:], ".")
+	if dot < 0 {
+		return "", "", fmt.Errorf("%q does not name a type, expected PACKAGE.TYPE", arg)
+	}
+	pkg, typeName := arg[:slash+1+dot], arg[slash+1+dot+1:]
+	if pkg == "" || typeName == "" {
+		return "", "", fmt.Errorf("%q does not name a type, expected PACKAGE.TYPE", arg)
+	}
+	return pkg, typeName, nil
+}
+
+// generateSchema builds and runs schemaProgram for the type typeName of pkg and returns its output.
+// Build errors are copied to stderr
+func generateSchema(pkg, typeName string, stderr io.Writer) ([]byte, error) {
+	var listed bytes.Buffer
+	list := exec.Command("go", "list", "-f", "{{.ImportPath}}\n{{.Dir}}", pkg)
+	list.Stdout = &listed
+	list.Stderr = stderr
+	if err := list.Run(); err != nil {
+		return nil, fmt.Errorf("cannot find package %s: %v", pkg, err)
+	}
+	importPath, dir, _ := strings.Cut(strings.TrimSpace(listed.String()), "\n")
+
+	// The program lives below the package so that it may also import internal packages
+	tmp, err := os.MkdirTemp(dir, ".configmanager-schema-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	var program bytes.Buffer
+	if err := schemaProgram.Execute(&program, struct{ ImportPath, Type string }{importPath, typeName}); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "main.go"), program.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	var schema bytes.Buffer
+	run := exec.Command("go", "run", ".")
+	run.Dir = tmp
+	run.Stdout = &schema
+	run.Stderr = stderr
+	if err := run.Run(); err != nil {
+		return nil, fmt.Errorf("cannot generate the schema of %s.%s: %v", importPath, typeName, err)
+	}
+	return schema.Bytes(), nil
+}
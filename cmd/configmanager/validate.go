@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/configManager"
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+)
+
+// runValidate parses every configuration file under the given paths, or the base path, with the loader
+// LoaderFactory picks for it and reports the files that fail to parse. With -schema, JSON and YAML files
+// are also validated against a JSON Schema
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flagSet := newFlagSet("validate", "[path ...]", stderr)
+	basePath := flagSet.String("base-path", "./configs", "directory to validate when no paths are given")
+	verbose := flagSet.Bool("v", false, "also report valid and skipped files")
+	schemaFile := flagSet.String("schema", "", "JSON Schema file to validate JSON and YAML files against")
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+
+	var schema *jsonschema.Schema
+	if *schemaFile != "" {
+		content, err := os.ReadFile(*schemaFile)
+		if err == nil {
+			schema, err = jsonschema.Compile(content)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "configmanager validate: %v\n", err)
+			return 2
+		}
+	}
+
+	paths := flagSet.Args()
+	if len(paths) == 0 {
+		paths = []string{*basePath}
+	}
+
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "configmanager validate: %v\n", err)
+			return 1
+		}
+	}
+
+	validated, failed := 0, 0
+	for _, file := range files {
+		loader, err := configManager.LoaderFactory(file)
+		if err != nil {
+			if *verbose {
+				fmt.Fprintf(stdout, "skip %s: %v\n", file, err)
+			}
+			continue
+		}
+
+		if schemaLoader, ok := loader.(configManager.SchemaLoader); ok {
+			schemaLoader.SetSchema(schema)
+		}
+
+		validated++
+		if _, err := loader.Load(file); err != nil {
+			failed++
+			// schema violations are reported one per line
+			for _, line := range strings.Split(describeError(file, err), "\n") {
+				fmt.Fprintf(stdout, "FAIL %s\n", line)
+			}
+		} else if *verbose {
+			fmt.Fprintf(stdout, "ok   %s\n", file)
+		}
+	}
+
+	fmt.Fprintf(stdout, "%d files validated, %d with errors\n", validated, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// describeError prefixes an error with the file it occurred in, unless its position already names it
+func describeError(file string, err error) string {
+	message := err.Error()
+	if strings.Contains(message, file) {
+		return message
+	}
+	return file + ": " + message
+}
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// IncludeKeys are the top-level keys of a structured document that pull in other files
+var IncludeKeys = []string{"include", "$import"}
+
+// Files reads the files referenced by include directives
+type Files interface {
+	ReadFile(name string) ([]byte, error)
+	// Resolve returns the files matching pattern, relative to the directory of the file from. Patterns
+	// without glob metacharacters are returned as is, so that a missing file is reported when read
+	Resolve(from, pattern string) ([]string, error)
+}
+
+// FilesFor returns Files reading from fsys, or from the local disk if fsys is nil
+func FilesFor(fsys fs.FS) Files {
+	if fsys == nil {
+		return osFiles{}
+	}
+	return fsFiles{fsys: fsys}
+}
+
+type osFiles struct{}
+
+func (osFiles) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFiles) Resolve(from, pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(from), pattern)
+	}
+	return glob(pattern, filepath.Glob)
+}
+
+type fsFiles struct {
+	fsys fs.FS
+}
+
+func (f fsFiles) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(f.fsys, name)
+}
+
+func (f fsFiles) Resolve(from, pattern string) ([]string, error) {
+	pattern = path.Join(path.Dir(from), pattern)
+	return glob(pattern, func(pattern string) ([]string, error) {
+		return fs.Glob(f.fsys, pattern)
+	})
+}
+
+// glob expands pattern in lexical order if it contains glob metacharacters
+func glob(pattern string, expand func(string) ([]string, error)) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	matches, err := expand(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern %q: %v", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// IncludeChain tracks the files currently being included to detect cycles
+type IncludeChain []string
+
+// Enter returns the chain extended with name, or an error if name is already being included
+func (c IncludeChain) Enter(name string) (IncludeChain, error) {
+	for _, included := range c {
+		if included == name {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(append([]string(nil), c...), name), " -> "))
+		}
+	}
+	return append(append(IncludeChain(nil), c...), name), nil
+}
+
+// Decoder parses the content of a file into a tree
+type Decoder func(content []byte, name string) (*tree.Node, error)
+
+// ResolveIncludes removes the include directives from the top level of root and returns the included
+// files merged in declared order, with the content of root merged on top. Each directive holds a path or
+// a list of paths, relative to the including file, which may be glob patterns. Included files are decoded
+// with decode and may include further files
+func ResolveIncludes(root *tree.Node, name string, files Files, decode Decoder) (*tree.Node, error) {
+	return resolveIncludes(root, name, files, decode, IncludeChain{name})
+}
+
+func resolveIncludes(root *tree.Node, name string, files Files, decode Decoder, chain IncludeChain) (*tree.Node, error) {
+	if root.Kind != tree.MapNode {
+		return root, nil
+	}
+
+	var patterns []*tree.Node
+	for _, key := range append([]string(nil), root.Keys...) {
+		if !isIncludeKey(key) {
+			continue
+		}
+		directive := root.Children[key]
+		root.Delete(key)
+		switch directive.Kind {
+		case tree.ScalarNode:
+			patterns = append(patterns, directive)
+		case tree.ListNode:
+			patterns = append(patterns, directive.Items...)
+		case tree.NullNode:
+		default:
+			return nil, fmt.Errorf("%s: %s must be a path or a list of paths", directive.Pos, key)
+		}
+	}
+	if len(patterns) == 0 {
+		return root, nil
+	}
+
+	result := tree.NewMap(root.Pos)
+	for _, pattern := range patterns {
+		if pattern.Kind != tree.ScalarNode {
+			return nil, fmt.Errorf("%s: include paths must be strings", pattern.Pos)
+		}
+		matches, err := files.Resolve(name, pattern.String())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", pattern.Pos, err)
+		}
+		for _, match := range matches {
+			included, err := includeFile(match, files, decode, chain)
+			if err != nil {
+				return nil, err
+			}
+			tree.Merge(result, included)
+		}
+	}
+
+	return tree.Merge(result, root), nil
+}
+
+// includeFile decodes an included file and resolves its own includes
+func includeFile(name string, files Files, decode Decoder, chain IncludeChain) (*tree.Node, error) {
+	chain, err := chain.Enter(name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := files.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("include %s: %v", name, err)
+	}
+	root, err := decode(content, name)
+	if err != nil {
+		return nil, err
+	}
+	return resolveIncludes(root, name, files, decode, chain)
+}
+
+func isIncludeKey(key string) bool {
+	for _, includeKey := range IncludeKeys {
+		if key == includeKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeLines decodes "key=value" lines into a flat tree, collecting "include=path" lines into a list
+func decodeLines(content []byte, name string) (*tree.Node, error) {
+	root := tree.NewMap(tree.Position{File: name})
+	includes := tree.NewList(tree.Position{File: name})
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		if key == "include" {
+			includes.Items = append(includes.Items, tree.NewScalar(value, tree.Position{File: name}))
+		} else if key != "" {
+			root.Set(key, tree.NewScalar(value, tree.Position{File: name}))
+		}
+	}
+	if len(includes.Items) > 0 {
+		root.Set("include", includes)
+	}
+	return root, nil
+}
+
+func TestResolveIncludes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/main":          {Data: []byte("include=base\ninclude=conf.d/*\nname=main")},
+		"app/base":          {Data: []byte("name=base\nlevel=info\nport=80")},
+		"app/conf.d/10-log": {Data: []byte("level=debug")},
+		"app/conf.d/20-net": {Data: []byte("include=../shared/net\nport=8080")},
+		"app/shared/net":    {Data: []byte("host=0.0.0.0\nport=81")},
+	}
+	files := FilesFor(fsys)
+
+	content, _ := files.ReadFile("app/main")
+	root, _ := decodeLines(content, "app/main")
+	root, err := ResolveIncludes(root, "app/main", files, decodeLines)
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	result, _, err := FlattenTree(root, keys.Upper)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{
+		"NAME":  "main",
+		"LEVEL": "debug",
+		"PORT":  "8080",
+		"HOST":  "0.0.0.0",
+	}, result, "includes should be merged in declared order underneath the including file")
+}
+
+func TestResolveIncludes_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		err   string
+	}{
+		{
+			name: "cycle",
+			files: fstest.MapFS{
+				"a": {Data: []byte("include=b")},
+				"b": {Data: []byte("include=c")},
+				"c": {Data: []byte("include=a")},
+			},
+			err: "include cycle: a -> b -> c -> a",
+		},
+		{
+			name: "missing file",
+			files: fstest.MapFS{
+				"a": {Data: []byte("include=missing")},
+			},
+			err: "include missing: open missing: file does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := FilesFor(tt.files)
+			content, _ := files.ReadFile("a")
+			root, _ := decodeLines(content, "a")
+			_, err := ResolveIncludes(root, "a", files, decodeLines)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -3,25 +3,75 @@ package internal
 import (
 	"fmt"
 	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
 )
 
-// FlattenMap recursively flattens nested maps into a single-level map
-func FlattenMap(data map[string]interface{}, prefix string) map[string]string {
-	result := make(map[string]string)
-	for key, value := range data {
-		envKey := prefix + strings.ToUpper(key)
-		switch v := value.(type) {
-		case string:
-			result[envKey] = v
-		case int, int64, float64, bool:
-			result[envKey] = fmt.Sprintf("%v", v)
-		case map[string]interface{}:
-			subMap := FlattenMap(v, envKey+"_")
-			for subKey, subValue := range subMap {
-				result[subKey] = subValue
-			}
+// Collector builds a flat map from key paths, reporting an error when two different paths normalize to the
+// same key
+type Collector struct {
+	normalizer keys.Normalizer
+	result     map[string]string
+	sources    map[string]string
+}
+
+// NewCollector creates a Collector that builds keys with normalizer
+func NewCollector(normalizer keys.Normalizer) *Collector {
+	return &Collector{
+		normalizer: normalizer,
+		result:     make(map[string]string),
+		sources:    make(map[string]string),
+	}
+}
+
+// Add sets the value of the key built from path. Adding the same path again overwrites the value
+func (c *Collector) Add(path []string, value string) error {
+	key := c.normalizer.Normalize(path...)
+	source := strings.Join(path, ".")
+	if previous, exists := c.sources[key]; exists && previous != source {
+		if previous > source {
+			previous, source = source, previous
 		}
+		return fmt.Errorf("keys %q and %q both normalize to %q", previous, source, key)
+	}
+	c.sources[key] = source
+	c.result[key] = value
+	return nil
+}
+
+// Result returns the collected key-value pairs
+func (c *Collector) Result() map[string]string {
+	return c.result
+}
+
+// FlattenMap recursively flattens nested maps into a single-level map, uppercasing keys and joining nested
+// keys with '_' after prefix. See Flatten for how arrays, nulls and collisions are handled
+func FlattenMap(data map[string]interface{}, prefix string) (map[string]string, map[string]bool, error) {
+	return Flatten(data, keys.Func(func(path ...string) string {
+		return prefix + keys.Upper.Normalize(path...)
+	}))
+}
+
+// Flatten recursively flattens nested maps into a single-level map, building keys from the path of each
+// value with normalizer. Arrays produce one indexed key per element (KEY_0, KEY_1, ...) and, when every
+// element is a scalar, a comma-joined KEY. Explicit nulls produce no value; their keys are returned in
+// the second map, so that they can be told apart from absent keys. Values of unsupported types or keys
+// that collide after normalization are reported as errors
+func Flatten(data map[string]interface{}, normalizer keys.Normalizer) (map[string]string, map[string]bool, error) {
+	root, err := tree.FromValue(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, index, err := FlattenTree(root, normalizer)
+	if err != nil {
+		return nil, nil, err
 	}
+	return result, NullKeys(index), nil
+}
 
-	return result
+// appendPath returns a copy of path with segment appended, so sibling paths never share a backing array
+func appendPath(path []string, segment string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), segment)
 }
@@ -3,6 +3,9 @@ package internal
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/LetsFocus/configManager/pkg/keys"
 )
 
 
@@ -12,6 +15,7 @@ func TestFlattenMap(t *testing.T) {
 		input    map[string]interface{}
 		prefix   string
 		expected map[string]string
+		nulls    map[string]bool
 	}{
 		{
 			name: "Empty map",
@@ -75,14 +79,149 @@ func TestFlattenMap(t *testing.T) {
 				"KEY1_KEY2_KEY3": "value3",
 			},
 		},
+		{
+			name: "Null values",
+			input: map[string]interface{}{
+				"key1": nil,
+				"nested": map[string]interface{}{
+					"key2": nil,
+				},
+			},
+			prefix: "",
+			expected: map[string]string{},
+			nulls:    map[string]bool{"KEY1": true, "NESTED_KEY2": true},
+		},
+		{
+			name: "Arrays of scalars",
+			input: map[string]interface{}{
+				"hosts": []interface{}{"a", "b", "c"},
+				"ports": []interface{}{80, 443},
+				"empty": []interface{}{},
+			},
+			prefix: "",
+			expected: map[string]string{
+				"HOSTS":   "a,b,c",
+				"HOSTS_0": "a",
+				"HOSTS_1": "b",
+				"HOSTS_2": "c",
+				"PORTS":   "80,443",
+				"PORTS_0": "80",
+				"PORTS_1": "443",
+				"EMPTY":   "",
+			},
+		},
+		{
+			name: "Arrays of maps",
+			input: map[string]interface{}{
+				"servers": []interface{}{
+					map[string]interface{}{"name": "web"},
+					map[string]interface{}{"name": "api"},
+				},
+			},
+			prefix: "",
+			expected: map[string]string{
+				"SERVERS_0_NAME": "web",
+				"SERVERS_1_NAME": "api",
+			},
+		},
+		{
+			name: "YAML decoded types",
+			input: map[string]interface{}{
+				"big":     uint64(18446744073709551615),
+				"large":   float64(1000000),
+				"created": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				"labels": map[interface{}]interface{}{
+					1:      "one",
+					"name": "two",
+				},
+			},
+			prefix: "",
+			expected: map[string]string{
+				"BIG":         "18446744073709551615",
+				"LARGE":       "1000000",
+				"CREATED":     "2024-01-02T03:04:05Z",
+				"LABELS_1":    "one",
+				"LABELS_NAME": "two",
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := FlattenMap(test.input, test.prefix)
+			result, nulls, err := FlattenMap(test.input, test.prefix)
+			if err != nil {
+				t.Fatalf("For input %v and prefix %q, unexpected error %v", test.input, test.prefix, err)
+			}
 			if !reflect.DeepEqual(result, test.expected) {
 				t.Errorf("For input %v and prefix %q, expected %v but got %v", test.input, test.prefix, test.expected, result)
 			}
+			if len(nulls) != len(test.nulls) || (len(nulls) > 0 && !reflect.DeepEqual(nulls, test.nulls)) {
+				t.Errorf("For input %v and prefix %q, expected null keys %v but got %v", test.input, test.prefix, test.nulls, nulls)
+			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestFlattenMap_UnsupportedType(t *testing.T) {
+	input := map[string]interface{}{
+		"nested": map[string]interface{}{
+			"channel": make(chan int),
+		},
+	}
+
+	result, _, err := FlattenMap(input, "")
+	if err == nil {
+		t.Fatalf("Expected an error for an unsupported type, got %v", result)
+	}
+	if err.Error() != "unsupported value type chan int for key nested.channel" {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	input := map[string]interface{}{
+		"db": map[string]interface{}{
+			"maxConns": 10,
+			"hosts":    []interface{}{"a", "b"},
+		},
+	}
+
+	result, _, err := Flatten(input, keys.UpperSnake)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expected := map[string]string{
+		"DB_MAX_CONNS": "10",
+		"DB_HOSTS":     "a,b",
+		"DB_HOSTS_0":   "a",
+		"DB_HOSTS_1":   "b",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+
+	result, _, err = Flatten(input, keys.DottedLower)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if result["db.maxconns"] != "10" || result["db.hosts.1"] != "b" {
+		t.Errorf("Unexpected dotted keys %v", result)
+	}
+}
+
+func TestFlatten_Collision(t *testing.T) {
+	input := map[string]interface{}{
+		"db": map[string]interface{}{
+			"maxConns":  10,
+			"max_conns": 20,
+		},
+	}
+
+	_, _, err := Flatten(input, keys.UpperSnake)
+	if err == nil {
+		t.Fatal("Expected a collision error")
+	}
+	if err.Error() != `keys "db.maxConns" and "db.max_conns" both normalize to "DB_MAX_CONNS"` {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// FlattenTree derives the flat view of a tree, building keys from the path of each node with normalizer.
+// Besides the key-value pairs it returns an index from every flat key, including those of maps, lists
+// and nulls, to its node. See Flatten for how lists, nulls and collisions are handled
+func FlattenTree(root *tree.Node, normalizer keys.Normalizer) (map[string]string, map[string]*tree.Node, error) {
+	collector := NewCollector(normalizer)
+	index := make(map[string]*tree.Node)
+	if root.Kind != tree.MapNode {
+		return nil, nil, fmt.Errorf("%s: configuration must be a mapping at the top level", root.Pos)
+	}
+	for _, key := range root.Keys {
+		if err := flattenNode(collector, index, []string{key}, root.Children[key]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return collector.Result(), index, nil
+}
+
+// flattenNode adds node to the collector under path, recursing into maps and lists
+func flattenNode(collector *Collector, index map[string]*tree.Node, path []string, node *tree.Node) error {
+	index[collector.normalizer.Normalize(path...)] = node
+	switch node.Kind {
+	case tree.MapNode:
+		for _, key := range node.Keys {
+			if err := flattenNode(collector, index, appendPath(path, key), node.Children[key]); err != nil {
+				return err
+			}
+		}
+	case tree.NullNode:
+		// Nulls have no value, so that defaults still apply; NullKeys finds them in the index
+	case tree.ListNode:
+		joined := make([]string, 0, len(node.Items))
+		scalars := true
+		for i, item := range node.Items {
+			if err := flattenNode(collector, index, appendPath(path, strconv.Itoa(i)), item); err != nil {
+				return err
+			}
+			if item.Kind == tree.MapNode || item.Kind == tree.ListNode {
+				scalars = false
+			} else {
+				joined = append(joined, item.String())
+			}
+		}
+		if scalars {
+			return collector.Add(path, strings.Join(joined, ","))
+		}
+	default:
+		if err := collector.Add(path, node.String()); err != nil {
+			if node.Pos.File != "" {
+				return fmt.Errorf("%s: %v", node.Pos, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NullKeys returns the keys of an index built by FlattenTree whose value is an explicit null
+func NullKeys(index map[string]*tree.Node) map[string]bool {
+	nulls := make(map[string]bool)
+	for key, node := range index {
+		if node.Kind == tree.NullNode {
+			nulls[key] = true
+		}
+	}
+	return nulls
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenTree(t *testing.T) {
+	root := tree.NewMap(tree.Position{File: "config.yaml", Line: 1, Column: 1})
+	servers := tree.NewList(tree.Position{File: "config.yaml", Line: 2, Column: 3})
+	server := tree.NewMap(tree.Position{File: "config.yaml", Line: 3, Column: 5})
+	server.Set("name", tree.NewScalar("primary", tree.Position{File: "config.yaml", Line: 3, Column: 11}))
+	server.Set("port", tree.NewScalar(int64(8080), tree.Position{File: "config.yaml", Line: 4, Column: 11}))
+	servers.Items = append(servers.Items, server)
+	root.Set("servers", servers)
+	root.Set("tags", &tree.Node{Kind: tree.ListNode, Items: []*tree.Node{tree.NewScalar("a", tree.Position{}), tree.NewScalar("b", tree.Position{})}})
+
+	result, index, err := FlattenTree(root, keys.Upper)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{
+		"SERVERS_0_NAME": "primary",
+		"SERVERS_0_PORT": "8080",
+		"TAGS_0":         "a",
+		"TAGS_1":         "b",
+		"TAGS":           "a,b",
+	}, result, "Flattened tree did not match expected")
+
+	assert.Same(t, servers, index["SERVERS"], "index should contain list nodes")
+	assert.Same(t, server, index["SERVERS_0"], "index should contain map nodes")
+	assert.Equal(t, "config.yaml:4:11", index["SERVERS_0_PORT"].Pos.String(), "index should keep positions")
+	assert.Equal(t, int64(8080), index["SERVERS_0_PORT"].Value, "index should keep value types")
+}
+
+func TestFlattenTree_NotAMap(t *testing.T) {
+	_, _, err := FlattenTree(tree.NewList(tree.Position{File: "config.json", Line: 1, Column: 1}), keys.Upper)
+	assert.EqualError(t, err, "config.json:1:1: configuration must be a mapping at the top level")
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// trie collects flat keys split into segments before they are turned into a tree
+type trie struct {
+	value    *string
+	children map[string]*trie
+}
+
+func (t *trie) child(segment string) *trie {
+	if t.children == nil {
+		t.children = make(map[string]*trie)
+	}
+	next, exists := t.children[segment]
+	if !exists {
+		next = &trie{}
+		t.children[segment] = next
+	}
+	return next
+}
+
+// Unflatten rebuilds nesting from flat keys such as PARENT_CHILD, splitting them on separator and
+// lowercasing every segment, so that flattening the result with keys.Upper yields the original keys.
+// Children numbered 0 to n-1 become lists, replacing a comma-joined value of the same list. A key that is
+// both a value and the parent of other keys keeps its value, and its children stay joined with separator
+// one level up. Values are typed where the flat form is unambiguous: integers, floats and booleans
+func Unflatten(values map[string]string, separator string) *tree.Node {
+	root := &trie{}
+	for key, value := range values {
+		node := root
+		for _, segment := range strings.Split(strings.ToLower(key), separator) {
+			node = node.child(segment)
+		}
+		value := value
+		node.value = &value
+	}
+
+	result := tree.NewMap(tree.Position{})
+	addChildren(result, root, "", separator)
+	return result
+}
+
+// addChildren adds the children of t to the map node parent, prefixing their keys with prefix
+func addChildren(parent *tree.Node, t *trie, prefix string, separator string) {
+	segments := make([]string, 0, len(t.children))
+	for segment := range t.children {
+		segments = append(segments, segment)
+	}
+	sort.Strings(segments)
+
+	for _, segment := range segments {
+		child := t.children[segment]
+		key := prefix + segment
+		switch {
+		case child.children == nil:
+			parent.Set(key, typedScalar(*child.value))
+		case child.value == nil || isJoinedList(child):
+			parent.Set(key, containerNode(child, separator))
+		default:
+			// The key holds a value of its own, so its children cannot be nested below it
+			parent.Set(key, typedScalar(*child.value))
+			addChildren(parent, child, key+separator, separator)
+		}
+	}
+}
+
+// containerNode converts a trie node with children into a list if they are numbered 0 to n-1, and into
+// a map otherwise
+func containerNode(t *trie, separator string) *tree.Node {
+	if isList(t) {
+		list := tree.NewList(tree.Position{})
+		for i := 0; i < len(t.children); i++ {
+			item := t.children[strconv.Itoa(i)]
+			if item.children == nil {
+				list.Items = append(list.Items, typedScalar(*item.value))
+			} else {
+				list.Items = append(list.Items, containerNode(item, separator))
+			}
+		}
+		return list
+	}
+
+	node := tree.NewMap(tree.Position{})
+	addChildren(node, t, "", separator)
+	return node
+}
+
+// isList reports whether the children of t are numbered 0 to n-1
+func isList(t *trie) bool {
+	for i := 0; i < len(t.children); i++ {
+		if _, exists := t.children[strconv.Itoa(i)]; !exists {
+			return false
+		}
+	}
+	return len(t.children) > 0
+}
+
+// isJoinedList reports whether the value of t is the comma-joined form of its list of scalar children
+func isJoinedList(t *trie) bool {
+	if !isList(t) {
+		return false
+	}
+	items := make([]string, len(t.children))
+	for i := range items {
+		item := t.children[strconv.Itoa(i)]
+		if item.children != nil || item.value == nil {
+			return false
+		}
+		items[i] = *item.value
+	}
+	return strings.Join(items, ",") == *t.value
+}
+
+// typedScalar converts a flat value into an int64, float64 or bool if it formats back to the same string,
+// and keeps it as a string otherwise
+func typedScalar(value string) *tree.Node {
+	if integer, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(integer, 10) == value {
+		return tree.NewScalar(integer, tree.Position{})
+	}
+	if float, err := strconv.ParseFloat(value, 64); err == nil && strconv.FormatFloat(float, 'f', -1, 64) == value {
+		return tree.NewScalar(float, tree.Position{})
+	}
+	if boolean, err := strconv.ParseBool(value); err == nil && strconv.FormatBool(boolean) == value {
+		return tree.NewScalar(boolean, tree.Position{})
+	}
+	return tree.NewScalar(value, tree.Position{})
+}
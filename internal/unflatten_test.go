@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnflatten(t *testing.T) {
+	values := map[string]string{
+		"DB_HOST":        "localhost",
+		"DB_PORT":        "5432",
+		"HOSTS":          "a,b",
+		"HOSTS_0":        "a",
+		"HOSTS_1":        "b",
+		"SERVERS_0_NAME": "primary",
+		"DEBUG":          "true",
+		"RATIO":          "0.5",
+		"VERSION":        "1.10",
+		"ZIP":            "007",
+		"NAME":           "app",
+		"NAME_SUFFIX":    "x",
+	}
+
+	root := Unflatten(values, "_")
+	assert.Equal(t, map[string]interface{}{
+		"db":          map[string]interface{}{"host": "localhost", "port": int64(5432)},
+		"hosts":       []interface{}{"a", "b"},
+		"servers":     []interface{}{map[string]interface{}{"name": "primary"}},
+		"debug":       true,
+		"ratio":       0.5,
+		"version":     "1.10",
+		"zip":         "007",
+		"name":        "app",
+		"name_suffix": "x",
+	}, root.Interface(), "nesting, lists and types should be rebuilt")
+
+	flat, _, err := FlattenTree(root, keys.Upper)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, values, flat, "flattening the result should yield the original keys")
+}
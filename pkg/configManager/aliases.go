@@ -0,0 +1,134 @@
+package configManager
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// aliasTable maps configuration keys to their deprecated names and remembers which deprecated keys were
+// already reported
+type aliasTable struct {
+	mu     sync.Mutex
+	table  map[string][]string
+	warned map[string]bool
+}
+
+func newAliasTable() *aliasTable {
+	return &aliasTable{table: make(map[string][]string), warned: make(map[string]bool)}
+}
+
+// WithAliases registers deprecated names for keys, e.g. {"DB_URL": {"DATABASE_URL", "PG_URL"}}. When a key
+// is not set, GetConfig, GetConfigWithDefault and Unmarshal fall back to the first of its aliases that is,
+// logging a deprecation warning once per alias. Keys and aliases are absolute, not relative to a Sub view.
+// Setting a key and one of its aliases, or two aliases, to different values makes LoadConfigs fail. Struct
+// fields can declare aliases with the aliases tag instead
+func WithAliases(aliases map[string][]string) Option {
+	return func(cm *Config) {
+		cm.aliases.mu.Lock()
+		defer cm.aliases.mu.Unlock()
+		for key, names := range aliases {
+			cm.aliases.table[key] = append(cm.aliases.table[key], names...)
+		}
+	}
+}
+
+// tableAliases returns the absolute aliases that WithAliases registered for a qualified key
+func (cm *Config) tableAliases(key string) []string {
+	cm.aliases.mu.Lock()
+	defer cm.aliases.mu.Unlock()
+	var aliases []string
+	for name, names := range cm.aliases.table {
+		if cm.normalizeKey(name) == key {
+			for _, alias := range names {
+				aliases = append(aliases, cm.normalizeKey(alias))
+			}
+		}
+	}
+	return aliases
+}
+
+// fieldAliases returns the absolute aliases of a struct field, from its aliases tag relative to the view
+// and from WithAliases
+func (cm *Config) fieldAliases(key, tag string) []string {
+	var aliases []string
+	for _, alias := range strings.Split(tag, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, cm.qualify(alias))
+		}
+	}
+	return append(aliases, cm.tableAliases(key)...)
+}
+
+// lookupKey returns the value of an absolute key from the cache or the environment
+func (cm *Config) lookupKey(key string) (string, bool) {
+	if value, found := cm.cache.Get(key); found {
+		return value, true
+	}
+	return os.LookupEnv(key)
+}
+
+// resolveAliases falls back to the aliases of key when value was not found, returning the key the value was
+// taken from. Every alias that is set is reported as deprecated, and an alias set to a different value
+// than key, or than the alias in use, is an error
+func (cm *Config) resolveAliases(key, value string, found bool, aliases []string) (string, string, bool, error) {
+	source := key
+	for _, alias := range aliases {
+		aliasValue, aliasFound := cm.lookupKey(alias)
+		if !aliasFound || alias == key {
+			continue
+		}
+		cm.warnDeprecated(alias, key)
+
+		switch {
+		case !found:
+			source, value, found = alias, aliasValue, true
+		case aliasValue != value && source == key:
+			return "", "", false, fmt.Errorf("conflicting values for %s and its deprecated alias %s", key, alias)
+		case aliasValue != value:
+			return "", "", false, fmt.Errorf("conflicting values for deprecated aliases %s and %s of %s", source, alias, key)
+		}
+	}
+	return source, value, found, nil
+}
+
+// warnDeprecated logs, once per alias, that a deprecated key is in use and where it was set
+func (cm *Config) warnDeprecated(alias, key string) {
+	cm.aliases.mu.Lock()
+	warned := cm.aliases.warned[alias]
+	cm.aliases.warned[alias] = true
+	cm.aliases.mu.Unlock()
+	if warned {
+		return
+	}
+
+	origin := Origin{Layer: LayerEnv}
+	cm.origins.mu.RLock()
+	if history := cm.origins.history[alias]; len(history) > 0 {
+		origin = effective(history)
+	}
+	cm.origins.mu.RUnlock()
+	cm.logf("Warning: configuration key %s is deprecated, use %s instead (set by %s)\n", alias, key, origin)
+}
+
+// checkAliases reports conflicting values between the keys and aliases registered with WithAliases
+func (cm *Config) checkAliases() error {
+	cm.aliases.mu.Lock()
+	keys := make([]string, 0, len(cm.aliases.table))
+	for key := range cm.aliases.table {
+		keys = append(keys, key)
+	}
+	cm.aliases.mu.Unlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		key = cm.normalizeKey(key)
+		value, found := cm.lookupKey(key)
+		if _, _, _, err := cm.resolveAliases(key, value, found, cm.tableAliases(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package configManager
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type aliasConfig struct {
+	URL   string   `env:"ALIAS_DB_URL" aliases:"ALIAS_DATABASE_URL,ALIAS_PG_URL" required:"true"`
+	Hosts []string `env:"ALIAS_HOSTS" aliases:"ALIAS_SERVERS"`
+}
+
+func TestAliases(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.env":       {Data: []byte("ALIAS_DATABASE_URL=postgres://old\nALIAS_OLD_LEVEL=debug\n")},
+		"configs/local.yaml": {Data: []byte("alias:\n  servers:\n    - a\n    - b\n")},
+	}
+	defer func() {
+		for _, key := range []string{"ALIAS_DATABASE_URL", "ALIAS_PG_URL", "ALIAS_DB_URL", "ALIAS_OLD_LEVEL", "ALIAS_LEVEL", "ALIAS_SERVERS", "ALIAS_SERVERS_0", "ALIAS_SERVERS_1"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	var log bytes.Buffer
+	config := New(WithFS(fsys), WithLogOutput(&log), WithAliases(map[string][]string{"ALIAS_LEVEL": {"ALIAS_OLD_LEVEL"}}))
+	assert.Equal(t, "debug", config.GetConfig("ALIAS_LEVEL"), "GetConfig should fall back to aliases from WithAliases")
+	assert.Equal(t, "debug", config.Sub("ALIAS").GetConfig("LEVEL"), "aliases should apply to Sub views")
+	assert.Equal(t, "info", config.GetConfigWithDefault("ALIAS_OTHER", "info"))
+
+	var cfg aliasConfig
+	assert.NoError(t, config.Unmarshal(&cfg, WithStrictKeys("ALIAS_DB", "ALIAS_DATABASE", "ALIAS_SERVERS")), "aliases should count as consumed keys")
+	assert.Equal(t, "postgres://old", cfg.URL, "Unmarshal should fall back to the aliases tag")
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts, "lists should be bound from the tree of an alias")
+	assert.NoError(t, config.Unmarshal(&cfg))
+
+	assert.Equal(t, 1, strings.Count(log.String(), "Warning: configuration key ALIAS_OLD_LEVEL is deprecated, use ALIAS_LEVEL instead (set by file (configs/.env))\n"), "deprecations should be logged once per key")
+	assert.Equal(t, 1, strings.Count(log.String(), "Warning: configuration key ALIAS_DATABASE_URL is deprecated, use ALIAS_DB_URL instead (set by file (configs/.env))\n"))
+
+	os.Setenv("ALIAS_PG_URL", "postgres://other")
+	assert.EqualError(t, config.Unmarshal(&cfg), "error setting field URL: conflicting values for deprecated aliases ALIAS_DATABASE_URL and ALIAS_PG_URL of ALIAS_DB_URL")
+	assert.Contains(t, log.String(), "Warning: configuration key ALIAS_PG_URL is deprecated, use ALIAS_DB_URL instead (set by env)")
+
+	os.Setenv("ALIAS_PG_URL", "postgres://old")
+	os.Setenv("ALIAS_DB_URL", "postgres://new")
+	assert.EqualError(t, config.Unmarshal(&cfg), "error setting field URL: conflicting values for ALIAS_DB_URL and its deprecated alias ALIAS_DATABASE_URL")
+
+	os.Setenv("ALIAS_LEVEL", "warn")
+	assert.Equal(t, "warn", config.GetConfig("ALIAS_LEVEL"), "GetConfig should keep the value of the key on conflicts")
+	assert.EqualError(t, config.LoadConfigs("configs"), "conflicting values for ALIAS_LEVEL and its deprecated alias ALIAS_OLD_LEVEL")
+}
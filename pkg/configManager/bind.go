@@ -0,0 +1,176 @@
+package configManager
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// nodeIndex maps flat keys to the tree nodes they were derived from, so that Unmarshal can bind lists and
+// nested structures without parsing them back out of strings
+type nodeIndex struct {
+	mu    sync.RWMutex
+	nodes map[string]*tree.Node
+}
+
+func newNodeIndex() *nodeIndex {
+	return &nodeIndex{nodes: make(map[string]*tree.Node)}
+}
+
+func (i *nodeIndex) reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.nodes = make(map[string]*tree.Node)
+}
+
+// merge adds the nodes of a newly loaded file, replacing nodes of earlier files with the same key
+func (i *nodeIndex) merge(nodes map[string]*tree.Node) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for key, node := range nodes {
+		i.nodes[key] = node
+	}
+}
+
+func (i *nodeIndex) get(key string) (*tree.Node, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	node, found := i.nodes[key]
+	return node, found
+}
+
+// treeNormalizer returns the normalizer used to derive flat keys from trees
+func (cm *Config) treeNormalizer() keys.Normalizer {
+	if cm.normalizer == nil {
+		return keys.Upper
+	}
+	return cm.normalizer
+}
+
+// structuredNode returns the tree node for a list or map field, unless a flat value that was set after
+// the tree was loaded, such as an environment variable, has replaced it
+func (cm *Config) structuredNode(key, flatValue string, flatFound bool) (*tree.Node, bool) {
+	node, found := cm.nodes.get(key)
+	if !found || node.Kind == tree.NullNode {
+		return nil, false
+	}
+	if flatFound && node.Kind == tree.ListNode && flatValue != joinedItems(node) {
+		return nil, false
+	}
+	return node, true
+}
+
+// joinedItems renders a list of scalars the way it appears in the flat view
+func joinedItems(node *tree.Node) string {
+	items := make([]string, 0, len(node.Items))
+	for _, item := range node.Items {
+		items = append(items, item.String())
+	}
+	return strings.Join(items, ",")
+}
+
+// bindNode sets field from a tree node, recursing into lists, maps and structs
+func (cm *Config) bindNode(field reflect.Value, node *tree.Node, path string) error {
+	if node.Kind == tree.NullNode {
+		return nil
+	}
+
+	switch {
+	case field.Kind() == reflect.Ptr:
+		value := reflect.New(field.Type().Elem())
+		if err := cm.bindNode(value.Elem(), node, path); err != nil {
+			return err
+		}
+		field.Set(value)
+	case field.Kind() == reflect.Slice && node.Kind == tree.ListNode:
+		slice := reflect.MakeSlice(field.Type(), len(node.Items), len(node.Items))
+		for i, item := range node.Items {
+			if err := cm.bindNode(slice.Index(i), item, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case field.Kind() == reflect.Map && node.Kind == tree.MapNode:
+		if field.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: map keys must be strings", path)
+		}
+		result := reflect.MakeMapWithSize(field.Type(), len(node.Keys))
+		for _, key := range node.Keys {
+			value := reflect.New(field.Type().Elem()).Elem()
+			if err := cm.bindNode(value, node.Children[key], path+"."+key); err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), value)
+		}
+		field.Set(result)
+	case isNested(field.Type()) && node.Kind == tree.MapNode:
+		return cm.bindStruct(field, node, path)
+	case node.Kind == tree.ScalarNode:
+		if err := setFieldValue(field, node.String()); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	default:
+		return fmt.Errorf("%s: cannot bind %s to a field of type %s", path, nodeKindName(node.Kind), field.Type())
+	}
+
+	return nil
+}
+
+// bindStruct sets the fields of a struct from the children of a map node, matching child keys against
+// the env tag or name of each field after normalization
+func (cm *Config) bindStruct(v reflect.Value, node *tree.Node, path string) error {
+	normalizer := cm.treeNormalizer()
+	children := make(map[string]*tree.Node, len(node.Keys))
+	for _, key := range node.Keys {
+		children[normalizer.Normalize(key)] = node.Children[key]
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		key := fieldType.Tag.Get("env")
+		if key == "" {
+			key = fieldType.Name
+		}
+		fieldPath := path + "." + key
+
+		child, found := children[normalizer.Normalize(key)]
+		if found && child.Kind != tree.NullNode {
+			if err := cm.bindNode(field, child, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if defaultValue := fieldType.Tag.Get("default"); defaultValue != "" {
+			if err := setFieldValue(field, defaultValue); err != nil {
+				return fmt.Errorf("%s: %v", fieldPath, err)
+			}
+		} else if fieldType.Tag.Get("required") == "true" {
+			return fmt.Errorf("missing required key: %s", fieldPath)
+		}
+	}
+
+	return nil
+}
+
+func nodeKindName(kind tree.Kind) string {
+	switch kind {
+	case tree.MapNode:
+		return "a map"
+	case tree.ListNode:
+		return "a list"
+	default:
+		return "a scalar"
+	}
+}
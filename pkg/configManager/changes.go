@@ -0,0 +1,53 @@
+package configManager
+
+import "sync"
+
+// Change describes a configuration value that was added or replaced by a load
+type Change struct {
+	Key      string
+	OldValue string
+	NewValue string
+	Origin   Origin
+}
+
+// listener is a change callback registered on a Config or on one of its Sub views
+type listener struct {
+	prefix string
+	fn     func(Change)
+}
+
+// listeners holds the change callbacks of a Config and all of its Sub views
+type listeners struct {
+	mu        sync.RWMutex
+	callbacks []listener
+}
+
+func (l *listeners) add(prefix string, fn func(Change)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.callbacks = append(l.callbacks, listener{prefix: prefix, fn: fn})
+}
+
+// notify calls every callback whose prefix contains change.Key, passing the key relative to that prefix
+func (l *listeners) notify(change Change) {
+	l.mu.RLock()
+	callbacks := append([]listener(nil), l.callbacks...)
+	l.mu.RUnlock()
+
+	for _, callback := range callbacks {
+		relative, ok := trimPrefix(callback.prefix, change.Key)
+		if !ok {
+			continue
+		}
+		scoped := change
+		scoped.Key = relative
+		callback.fn(scoped)
+	}
+}
+
+// OnChange registers fn to be called whenever a load adds a key or changes its value. Callbacks run
+// synchronously, in registration order, after the new value is stored. On a Sub view only changes below
+// its prefix are reported, with keys relative to the prefix
+func (cm *Config) OnChange(fn func(Change)) {
+	cm.watchers.add(cm.prefix, fn)
+}
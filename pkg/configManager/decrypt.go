@@ -0,0 +1,167 @@
+package configManager
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/LetsFocus/configManager/pkg/crypt"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// WithDecryptionKeyFile reads the key that decrypts ENC[...] values from a file, instead of from the
+// CONFIGMANAGER_KEY or CONFIGMANAGER_KEY_FILE environment variables
+func WithDecryptionKeyFile(path string) Option {
+	return func(cm *Config) {
+		cm.keyFile = path
+	}
+}
+
+// WithDecryptionKeyEnv reads the key that decrypts ENC[...] values from the environment variable name, or
+// from the file named by name_FILE, instead of CONFIGMANAGER_KEY
+func WithDecryptionKeyEnv(name string) Option {
+	return func(cm *Config) {
+		cm.keyEnv = name
+	}
+}
+
+// decryptionKey loads the key configured for the Config. It is read again on every load, so that a
+// rotated key is picked up by LoadConfigs
+func (cm *Config) decryptionKey() (crypt.Key, error) {
+	if cm.keyFile != "" {
+		return crypt.ReadKeyFile(cm.keyFile)
+	}
+	name := cm.keyEnv
+	if name == "" {
+		name = crypt.DefaultKeyEnv
+	}
+	key, err := crypt.KeyFromEnv(name)
+	if err == crypt.ErrNoKey {
+		return nil, fmt.Errorf("%v, set %s or %s_FILE", err, name, name)
+	}
+	return key, err
+}
+
+// encryptionNames wraps the normalizer of a flat loader and records, for every normalized key, the name
+// its values are encrypted under: the key as written, uppercased like the CLI does
+type encryptionNames struct {
+	normalizer keys.Normalizer
+	names      map[string]string
+}
+
+// Normalize normalizes path with the wrapped normalizer and records the name of the key
+func (n *encryptionNames) Normalize(path ...string) string {
+	key := n.normalizer.Normalize(path...)
+	n.names[key] = keys.Upper.Normalize(path...)
+	return key
+}
+
+// name returns the name the value of a normalized key is encrypted under
+func (n *encryptionNames) name(key string) string {
+	if n == nil {
+		return key
+	}
+	if name, ok := n.names[key]; ok {
+		return name
+	}
+	return key
+}
+
+// decryptValues replaces the encrypted values of a flat file by their plaintext. Values are bound to
+// the key as written, so names maps the normalized keys back; it is nil if no normalizer is configured
+func (cm *Config) decryptValues(configs map[string]string, names *encryptionNames) error {
+	keys := make([]string, 0, len(configs))
+	for key, value := range configs {
+		if crypt.IsEncrypted(value) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	decryptionKey, err := cm.decryptionKey()
+	if err != nil {
+		return fmt.Errorf("%s is encrypted: %v", keys[0], err)
+	}
+	for _, key := range keys {
+		plaintext, _, err := crypt.Decrypt(decryptionKey, names.name(key), configs[key])
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		configs[key] = plaintext
+	}
+	return nil
+}
+
+// decryptTree replaces the encrypted string scalars of a tree by their plaintext, restoring the type of
+// values encrypted from numbers, booleans or timestamps. The key is only loaded if the tree holds
+// encrypted values
+func (cm *Config) decryptTree(root *tree.Node) error {
+	var decryptionKey crypt.Key
+	var walk func(node *tree.Node, path []string) error
+	walk = func(node *tree.Node, path []string) error {
+		switch node.Kind {
+		case tree.MapNode:
+			for _, key := range node.Keys {
+				if err := walk(node.Children[key], append(path, key)); err != nil {
+					return err
+				}
+			}
+		case tree.ListNode:
+			for i, item := range node.Items {
+				if err := walk(item, append(path, strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+		case tree.ScalarNode:
+			value, ok := node.Value.(string)
+			if !ok || !crypt.IsEncrypted(value) {
+				return nil
+			}
+			if decryptionKey == nil {
+				var err error
+				if decryptionKey, err = cm.decryptionKey(); err != nil {
+					return fmt.Errorf("%s: value is encrypted: %v", node.Pos, err)
+				}
+			}
+			// envelopes are bound to the key as the CLI flattens it, whatever the normalizer
+			plaintext, valueType, err := crypt.Decrypt(decryptionKey, keys.Upper.Normalize(path...), value)
+			if err != nil {
+				return fmt.Errorf("%s: %v", node.Pos, err)
+			}
+			if node.Value, err = decryptedValue(plaintext, valueType); err != nil {
+				return fmt.Errorf("%s: %v", node.Pos, err)
+			}
+		}
+		return nil
+	}
+	return walk(root, nil)
+}
+
+// decryptedValue converts a decrypted value back into the scalar type it was encrypted from
+func decryptedValue(plaintext, valueType string) (interface{}, error) {
+	var value interface{}
+	var err error
+	switch valueType {
+	case crypt.TypeInteger:
+		if value, err = strconv.ParseInt(plaintext, 10, 64); err != nil {
+			value, err = strconv.ParseUint(plaintext, 10, 64)
+		}
+	case crypt.TypeNumber:
+		value, err = strconv.ParseFloat(plaintext, 64)
+	case crypt.TypeBoolean:
+		value, err = strconv.ParseBool(plaintext)
+	case crypt.TypeTimestamp:
+		value, err = time.Parse(time.RFC3339Nano, plaintext)
+	default:
+		return plaintext, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decrypted value is not a valid %s", valueType)
+	}
+	return value, nil
+}
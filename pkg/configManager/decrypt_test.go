@@ -0,0 +1,82 @@
+package configManager
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/LetsFocus/configManager/pkg/crypt"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryption(t *testing.T) {
+	key, _ := crypt.GenerateKey()
+	password, _ := crypt.Encrypt(key, "CRYPT_TEST_PASSWORD", "s3cret", crypt.TypeString)
+	token, _ := crypt.Encrypt(key, "CRYPT_TEST_TOKENS_0", "t0ken", crypt.TypeString)
+	port, _ := crypt.Encrypt(key, "CRYPT_TEST_PORT", "5432", crypt.TypeInteger)
+	keyFile := filepath.Join(t.TempDir(), "key")
+	os.WriteFile(keyFile, []byte(key.String()), 0600)
+
+	fsys := fstest.MapFS{
+		"configs/.env":       {Data: []byte("CRYPT_TEST_PASSWORD=" + password + "\nCRYPT_TEST_USER=app\n")},
+		"configs/local.yaml": {Data: []byte("crypt_test:\n  tokens:\n    - " + token + "\n  port: " + port + "\n")},
+		"configs/moved.env":  {Data: []byte("CRYPT_TEST_USER=" + password + "\n")},
+	}
+	defer func() {
+		for _, key := range []string{"CRYPT_TEST_PASSWORD", "CRYPT_TEST_USER", "CRYPT_TEST_TOKENS", "CRYPT_TEST_TOKENS_0", "CRYPT_TEST_PORT", "CRYPT_TEST_KEY"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys), WithLogOutput(io.Discard), WithDecryptionKeyFile(keyFile))
+	assert.Equal(t, "s3cret", config.GetConfig("CRYPT_TEST_PASSWORD"), "encrypted values of flat files should be decrypted")
+	assert.Equal(t, "app", config.GetConfig("CRYPT_TEST_USER"), "plain values should be kept")
+
+	var cfg struct {
+		Tokens []string `env:"CRYPT_TEST_TOKENS"`
+	}
+	assert.NoError(t, config.Unmarshal(&cfg))
+	assert.Equal(t, []string{"t0ken"}, cfg.Tokens, "encrypted values of trees should be decrypted")
+	node, _ := config.nodes.get("CRYPT_TEST_PORT")
+	assert.Equal(t, int64(5432), node.Value, "decrypted values should get back their type")
+
+	err := config.loadFile("configs/moved.env")
+	assert.EqualError(t, err, "error loading file configs/moved.env: CRYPT_TEST_USER: cannot decrypt value: wrong key, value of another key or corrupted data", "envelopes should not decrypt under another key")
+
+	os.Setenv("CRYPT_TEST_KEY", key.String())
+	config = New(WithFS(fsys), WithLogOutput(io.Discard), WithDecryptionKeyEnv("CRYPT_TEST_KEY"))
+	assert.Equal(t, "s3cret", config.GetConfig("CRYPT_TEST_PASSWORD"), "the key should be read from the environment")
+
+	err = config.loadFile("configs/local.yaml")
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	os.Unsetenv("CRYPT_TEST_KEY")
+	err = config.loadFile("configs/.env")
+	assert.EqualError(t, err, "error loading file configs/.env: CRYPT_TEST_PASSWORD is encrypted: no encryption key is configured, set CRYPT_TEST_KEY or CRYPT_TEST_KEY_FILE")
+	err = config.loadFile("configs/local.yaml")
+	assert.EqualError(t, err, "error loading file configs/local.yaml: configs/local.yaml:3:7: value is encrypted: no encryption key is configured, set CRYPT_TEST_KEY or CRYPT_TEST_KEY_FILE")
+
+	other, _ := crypt.GenerateKey()
+	os.Setenv("CRYPT_TEST_KEY", other.String())
+	err = config.loadFile("configs/.env")
+	assert.EqualError(t, err, "error loading file configs/.env: CRYPT_TEST_PASSWORD: cannot decrypt value: wrong key, value of another key or corrupted data")
+}
+
+func TestDecryption_KeyNormalizer(t *testing.T) {
+	key, _ := crypt.GenerateKey()
+	secret, _ := crypt.Encrypt(key, "cryptTestSecret", "s3cret", crypt.TypeString)
+	keyFile := filepath.Join(t.TempDir(), "key")
+	os.WriteFile(keyFile, []byte(key.String()), 0600)
+
+	fsys := fstest.MapFS{
+		"configs/.env": {Data: []byte("cryptTestSecret=" + secret + "\n")},
+	}
+	defer os.Unsetenv("CRYPT_TEST_SECRET")
+
+	config := New(WithFS(fsys), WithLogOutput(io.Discard), WithDecryptionKeyFile(keyFile), WithKeyNormalizer(keys.UpperSnake))
+	assert.NoError(t, config.loadFile("configs/.env"), "values should be decrypted under the key as written")
+	assert.Equal(t, "s3cret", config.GetConfig("CRYPT_TEST_SECRET"))
+}
@@ -2,25 +2,79 @@ package env
 
 import (
 	"bufio"
-	"os"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
 	"strings"
+
+	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/keys"
 )
 
+// includeDirective starts a line that loads other .env files at that point, e.g. `#include common.env`
+const includeDirective = "#include "
+
 // EnvLoader implements ConfigLoader for .env files
-type EnvLoader struct{}
+type EnvLoader struct {
+	normalizer keys.Normalizer
+	fsys       fs.FS
+}
+
+// SetKeyNormalizer sets the normalizer applied to keys. Keys are kept as written when none is set
+func (e *EnvLoader) SetKeyNormalizer(normalizer keys.Normalizer) {
+	e.normalizer = normalizer
+}
+
+// SetFS makes Load and include directives read files from fsys instead of the local disk
+func (e *EnvLoader) SetFS(fsys fs.FS) {
+	e.fsys = fsys
+}
 
-// Load parses .env files and returns key-value pairs
+// Load parses .env files and returns key-value pairs. A `#include <path>` line loads the files matching
+// path, relative to the including file and possibly a glob pattern, as if their lines appeared there
 func (e *EnvLoader) Load(filePath string) (map[string]string, error) {
-	file, err := os.Open(filePath)
+	files := internal.FilesFor(e.fsys)
+	content, err := files.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	configs := make(map[string]string)
-	scanner := bufio.NewScanner(file)
+	configs := internal.NewCollector(e.keyNormalizer())
+	if err := e.parse(configs, bytes.NewReader(content), filePath, files, internal.IncludeChain{filePath}); err != nil {
+		return nil, err
+	}
+	return configs.Result(), nil
+}
+
+// LoadReader parses .env content from a reader and returns key-value pairs. Include paths are relative to
+// the working directory
+func (e *EnvLoader) LoadReader(r io.Reader) (map[string]string, error) {
+	configs := internal.NewCollector(e.keyNormalizer())
+	if err := e.parse(configs, r, "", internal.FilesFor(e.fsys), nil); err != nil {
+		return nil, err
+	}
+	return configs.Result(), nil
+}
+
+func (e *EnvLoader) keyNormalizer() keys.Normalizer {
+	if e.normalizer == nil {
+		return keys.AsIs
+	}
+	return e.normalizer
+}
+
+// parse adds the key-value pairs of .env content to configs, following include directives
+func (e *EnvLoader) parse(configs *internal.Collector, r io.Reader, fileName string, files internal.Files, chain internal.IncludeChain) error {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, includeDirective) {
+			if err := e.include(configs, strings.TrimSpace(line[len(includeDirective):]), fileName, files, chain); err != nil {
+				return err
+			}
+			continue
+		}
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
@@ -28,8 +82,32 @@ func (e *EnvLoader) Load(filePath string) (map[string]string, error) {
 		if len(parts) == 2 {
 			key := strings.TrimSpace(parts[0])
 			value := strings.TrimSpace(parts[1])
-			configs[key] = value
+			if err := configs.Add([]string{key}, value); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// include parses every file matching pattern, in lexical order
+func (e *EnvLoader) include(configs *internal.Collector, pattern, fileName string, files internal.Files, chain internal.IncludeChain) error {
+	matches, err := files.Resolve(fileName, strings.Trim(pattern, `"'`))
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		included, err := chain.Enter(match)
+		if err != nil {
+			return err
+		}
+		content, err := files.ReadFile(match)
+		if err != nil {
+			return fmt.Errorf("include %s: %v", match, err)
+		}
+		if err := e.parse(configs, bytes.NewReader(content), match, files, included); err != nil {
+			return err
 		}
 	}
-	return configs, scanner.Err()
+	return nil
 }
@@ -2,8 +2,11 @@ package env
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/LetsFocus/configManager/pkg/keys"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -122,3 +125,41 @@ func TestEnvLoader_Load(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvLoader_LoadReader(t *testing.T) {
+	loader := &EnvLoader{}
+	result, err := loader.LoadReader(strings.NewReader("KEY1=value1\nKEY2=value2\n"))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"KEY1": "value1", "KEY2": "value2"}, result, "Loaded configuration did not match expected")
+}
+
+func TestEnvLoader_SetKeyNormalizer(t *testing.T) {
+	loader := &EnvLoader{}
+	loader.SetKeyNormalizer(keys.DottedLower)
+
+	result, err := loader.LoadReader(strings.NewReader("DB_URL=postgres\nServerPort=8080\n"))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"db.url": "postgres", "serverport": "8080"}, result, "keys should be normalized")
+
+	_, err = loader.LoadReader(strings.NewReader("DB_URL=a\ndb.url=b\n"))
+	assert.EqualError(t, err, `keys "DB_URL" and "db.url" both normalize to "db.url"`, "collisions should be reported")
+}
+
+func TestEnvLoader_Include(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.env":              {Data: []byte("NAME=app\n#include common.env\nLEVEL=warn\n#include \"conf.d/*.env\"\n")},
+		"configs/common.env":        {Data: []byte("NAME=common\nLEVEL=info\nPORT=80\n")},
+		"configs/conf.d/10-net.env": {Data: []byte("PORT=8080\n")},
+		"configs/a.env":             {Data: []byte("#include b.env\n")},
+		"configs/b.env":             {Data: []byte("#include a.env\n")},
+	}
+
+	loader := &EnvLoader{}
+	loader.SetFS(fsys)
+	result, err := loader.Load("configs/.env")
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"NAME": "common", "LEVEL": "warn", "PORT": "8080"}, result, "included lines should apply where the directive appears")
+
+	_, err = loader.Load("configs/a.env")
+	assert.EqualError(t, err, "include cycle: configs/a.env -> configs/b.env -> configs/a.env")
+}
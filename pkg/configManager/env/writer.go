@@ -0,0 +1,43 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// EnvWriter writes configuration as a .env file
+type EnvWriter struct{}
+
+// Write flattens the tree into KEY=value lines, sorted by key, with nested keys uppercased and joined with
+// '_' as the other loaders do. Values with surrounding whitespace cannot be written, as EnvLoader trims it
+func (e *EnvWriter) Write(w io.Writer, root *tree.Node) error {
+	configs, _, err := internal.FlattenTree(root, keys.Upper)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(configs))
+	for key := range configs {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	out := bufio.NewWriter(w)
+	for _, key := range names {
+		if strings.ContainsAny(configs[key], "\r\n") {
+			return fmt.Errorf("value of %s spans several lines, which .env files cannot represent", key)
+		}
+		if strings.TrimSpace(configs[key]) != configs[key] {
+			return fmt.Errorf("value of %s has surrounding whitespace, which .env files cannot represent", key)
+		}
+		fmt.Fprintf(out, "%s=%s\n", key, configs[key])
+	}
+	return out.Flush()
+}
@@ -0,0 +1,29 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvWriter_Unrepresentable(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		err   string
+	}{
+		{name: "Surrounding whitespace", value: map[string]interface{}{"padded": " spaced value "}, err: "value of PADDED has surrounding whitespace, which .env files cannot represent"},
+		{name: "Several lines", value: map[string]interface{}{"cert": "line one\nline two"}, err: "value of CERT spans several lines, which .env files cannot represent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := tree.FromValue(tt.value)
+			assert.NoError(t, err, "Did not expect an error but got one")
+			err = (&EnvWriter{}).Write(&bytes.Buffer{}, root)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
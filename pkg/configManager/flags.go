@@ -0,0 +1,150 @@
+package configManager
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// flagOverrides holds the values set by command-line flags, so that they are applied again on top of
+// every reload
+type flagOverrides struct {
+	mu     sync.Mutex
+	keys   []string
+	values map[string]Origin
+}
+
+func (o *flagOverrides) set(key string, origin Origin) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.values == nil {
+		o.values = make(map[string]Origin)
+	}
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = origin
+}
+
+// each calls fn for every flag value in the order the flags were first set
+func (o *flagOverrides) each(fn func(key string, origin Origin)) {
+	o.mu.Lock()
+	keys := append([]string(nil), o.keys...)
+	values := make(map[string]Origin, len(o.values))
+	for key, origin := range o.values {
+		values[key] = origin
+	}
+	o.mu.Unlock()
+
+	for _, key := range keys {
+		fn(key, values[key])
+	}
+}
+
+// flagValue is a flag.Value that stores what is set on the command line under the configuration key of a
+// struct field. It also implements the Type method of pflag.Value, so flag sets can be added to pflag
+// with AddGoFlagSet
+type flagValue struct {
+	cm        *Config
+	key       string
+	name      string
+	fieldType reflect.Type
+	value     string
+	changed   bool
+}
+
+// String returns the value set on the command line
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// Set validates value against the type of the field and stores it. Repeating a flag for a slice field
+// appends to the list
+func (f *flagValue) Set(value string) error {
+	if f.fieldType.Kind() == reflect.Slice && f.changed {
+		value = f.value + "," + value
+	}
+	if err := setFieldValue(reflect.New(f.fieldType).Elem(), value); err != nil {
+		return err
+	}
+
+	f.value, f.changed = value, true
+	origin := Origin{Layer: LayerFlag, Path: "--" + f.name, Value: value}
+	f.cm.flags.set(f.key, origin)
+	f.cm.set(f.key, value, origin)
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value, e.g. --verbose
+func (f *flagValue) IsBoolFlag() bool {
+	return f.fieldType.Kind() == reflect.Bool
+}
+
+// Type names the type of the flag for pflag
+func (f *flagValue) Type() string {
+	return f.fieldType.String()
+}
+
+// BindFlags defines a flag on flagSet for every field of target, a pointer to a struct, that has a flag
+// tag. The usage text comes from the desc tag and the displayed default from the default tag. Values set
+// on the command line are stored under the same keys Unmarshal reads, taking precedence over environment
+// variables and configuration files, including after a reload. Use pflag's AddGoFlagSet to add the flags
+// to a pflag.FlagSet
+func (cm *Config) BindFlags(flagSet *flag.FlagSet, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("target must be a pointer to a struct")
+	}
+
+	return cm.bindFlags(flagSet, v.Elem().Type())
+}
+
+func (cm *Config) bindFlags(flagSet *flag.FlagSet, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+
+		// Recursive call for nested structs
+		if isNested(fieldType.Type) {
+			if err := cm.bindFlags(flagSet, fieldType.Type); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := fieldType.Tag.Get("flag")
+		if name == "" {
+			continue
+		}
+		if flagSet.Lookup(name) != nil {
+			return fmt.Errorf("flag --%s is defined by more than one field", name)
+		}
+
+		value := &flagValue{cm: cm, key: cm.fieldKey(fieldType), name: name, fieldType: fieldType.Type}
+		flagSet.Var(value, name, fieldType.Tag.Get("desc"))
+		flagSet.Lookup(name).DefValue = fieldType.Tag.Get("default")
+	}
+	return nil
+}
+
+// ParseFlags defines the flags of target, see BindFlags, and parses them from os.Args. It returns
+// flag.ErrHelp if -h or --help is given
+func (cm *Config) ParseFlags(target interface{}) error {
+	flagSet := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	if err := cm.BindFlags(flagSet, target); err != nil {
+		return err
+	}
+	return flagSet.Parse(os.Args[1:])
+}
+
+// applyFlags stores the values set on the command line again after a reload
+func (cm *Config) applyFlags() {
+	cm.flags.each(func(key string, origin Origin) {
+		cm.set(key, origin.Value, origin)
+	})
+}
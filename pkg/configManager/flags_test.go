@@ -0,0 +1,57 @@
+package configManager
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindFlags(t *testing.T) {
+	type configStruct struct {
+		DBURL   string   `env:"FLAG_TEST_DB_URL" flag:"db-url" desc:"database connection string" default:"postgres://localhost"`
+		Port    int      `env:"FLAG_TEST_PORT" flag:"port" default:"80"`
+		Verbose bool     `env:"FLAG_TEST_VERBOSE" flag:"verbose"`
+		Hosts   []string `env:"FLAG_TEST_HOSTS" flag:"host"`
+		Name    string   `env:"FLAG_TEST_NAME"`
+	}
+
+	fsys := fstest.MapFS{
+		"configs/.env": {Data: []byte("FLAG_TEST_PORT=8080\nFLAG_TEST_NAME=file\n")},
+	}
+	defer func() {
+		for _, key := range []string{"FLAG_TEST_DB_URL", "FLAG_TEST_PORT", "FLAG_TEST_VERBOSE", "FLAG_TEST_HOSTS", "FLAG_TEST_NAME"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys))
+	os.Setenv("FLAG_TEST_DB_URL", "postgres://env")
+
+	var cfg configStruct
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.NoError(t, config.BindFlags(flagSet, &cfg), "BindFlags should not return an error")
+	assert.Nil(t, flagSet.Lookup("name"), "fields without a flag tag should not get a flag")
+
+	var usage bytes.Buffer
+	flagSet.SetOutput(&usage)
+	flagSet.PrintDefaults()
+	assert.Contains(t, usage.String(), "database connection string (default postgres://localhost)", "usage should come from the desc and default tags")
+
+	err := flagSet.Parse([]string{"--db-url", "postgres://flag", "--port=9090", "--verbose", "--host", "a", "--host", "b"})
+	assert.NoError(t, err, "Parse should not return an error")
+
+	assert.NoError(t, config.Unmarshal(&cfg), "Unmarshal should not return an error")
+	assert.Equal(t, configStruct{DBURL: "postgres://flag", Port: 9090, Verbose: true, Hosts: []string{"a", "b"}, Name: "file"}, cfg)
+
+	assert.NoError(t, config.LoadConfigs("configs"))
+	assert.Equal(t, "9090", config.GetConfig("FLAG_TEST_PORT"), "flags should take precedence over files after a reload")
+	origin, _ := config.Origin("FLAG_TEST_PORT")
+	assert.Equal(t, "flag (--port)", origin.String())
+
+	err = flagSet.Parse([]string{"--port", "eighty"})
+	assert.Error(t, err, "values that do not match the field type should be rejected")
+}
@@ -0,0 +1,196 @@
+package hcl
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// HCLLoader implements ConfigLoader for .hcl files
+type HCLLoader struct {
+	normalizer keys.Normalizer
+}
+
+// SetKeyNormalizer sets the normalizer applied to attribute paths. Keys are uppercased and joined with '_'
+// when none is set
+func (h *HCLLoader) SetKeyNormalizer(normalizer keys.Normalizer) {
+	h.normalizer = normalizer
+}
+
+// Load parses HCL2 files and returns key-value pairs. Attributes are evaluated without variables or
+// functions, and blocks become key segments, so `db "primary" { port = 5432 }` yields DB_PRIMARY_PORT
+func (h *HCLLoader) Load(filePath string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.parse(content, filePath)
+}
+
+// LoadReader parses HCL content from a reader and returns key-value pairs
+func (h *HCLLoader) LoadReader(r io.Reader) (map[string]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.parse(content, "<reader>")
+}
+
+// LoadTree parses HCL content from a reader into a tree that keeps value types and source positions
+func (h *HCLLoader) LoadTree(r io.Reader, fileName string) (*tree.Node, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTree(content, fileName)
+}
+
+// parse evaluates HCL content, using fileName to label diagnostics
+func (h *HCLLoader) parse(content []byte, fileName string) (map[string]string, error) {
+	root, err := decodeTree(content, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := h.normalizer
+	if normalizer == nil {
+		normalizer = keys.Upper
+	}
+
+	configs, _, err := internal.FlattenTree(root, normalizer)
+	return configs, err
+}
+
+// decodeTree parses HCL content and evaluates its body into a tree
+func decodeTree(content []byte, fileName string) (*tree.Node, error) {
+	file, diags := hclsyntax.ParseConfig(content, fileName, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	body := file.Body.(*hclsyntax.Body)
+	return decodeBody(body, position(body.SrcRange))
+}
+
+// position converts the start of an HCL source range into a tree position
+func position(rng hcl.Range) tree.Position {
+	return tree.Position{File: rng.Filename, Line: rng.Start.Line, Column: rng.Start.Column}
+}
+
+// decodeBody evaluates the attributes and nested blocks of a body into a map node
+func decodeBody(body *hclsyntax.Body, pos tree.Position) (*tree.Node, error) {
+	node := tree.NewMap(pos)
+
+	// Attributes are kept in a map by the parser; restore their source order
+	attributes := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attributes = append(attributes, attr)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].SrcRange.Start.Byte < attributes[j].SrcRange.Start.Byte
+	})
+
+	for _, attr := range attributes {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		decoded, err := decodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", attr.SrcRange, err)
+		}
+		child, err := tree.FromValueAt(decoded, position(attr.Expr.Range()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", attr.SrcRange, err)
+		}
+		node.Set(attr.Name, child)
+	}
+
+	for _, block := range body.Blocks {
+		blockNode, err := decodeBody(block.Body, position(block.DefRange()))
+		if err != nil {
+			return nil, err
+		}
+
+		// Each label nests the block one level deeper
+		target := node
+		for _, segment := range append([]string{block.Type}, block.Labels...) {
+			next, exists := target.Children[segment]
+			if !exists {
+				next = tree.NewMap(blockNode.Pos)
+				target.Set(segment, next)
+			} else if next.Kind != tree.MapNode {
+				return nil, fmt.Errorf("%s: block %q conflicts with an attribute of the same name", block.DefRange(), segment)
+			}
+			target = next
+		}
+		// Repeated blocks are merged recursively, so nested blocks of each are kept
+		tree.Merge(target, blockNode)
+	}
+
+	return node, nil
+}
+
+// decodeValue converts an evaluated cty value into the plain Go types understood by FlattenMap
+func decodeValue(value cty.Value) (interface{}, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+	if !value.IsKnown() {
+		return nil, fmt.Errorf("value is not known without an evaluation context")
+	}
+
+	valueType := value.Type()
+	switch {
+	case valueType == cty.String:
+		return value.AsString(), nil
+	case valueType == cty.Bool:
+		return value.True(), nil
+	case valueType == cty.Number:
+		number := value.AsBigFloat()
+		if number.IsInt() {
+			if integer, accuracy := number.Int64(); accuracy == big.Exact {
+				return integer, nil
+			}
+		}
+		float, _ := number.Float64()
+		return float, nil
+	case valueType.IsObjectType() || valueType.IsMapType():
+		result := make(map[string]interface{})
+		for it := value.ElementIterator(); it.Next(); {
+			key, element := it.Element()
+			decoded, err := decodeValue(element)
+			if err != nil {
+				return nil, err
+			}
+			result[key.AsString()] = decoded
+		}
+		return result, nil
+	case valueType.IsListType() || valueType.IsTupleType() || valueType.IsSetType():
+		result := make([]interface{}, 0, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			_, element := it.Element()
+			decoded, err := decodeValue(element)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, decoded)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", valueType.FriendlyName())
+	}
+}
@@ -0,0 +1,167 @@
+package hcl
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHCLLoader_Load(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileContent string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "Valid HCL with attributes",
+			fileContent: `
+name    = "api"
+port    = 8080
+ratio   = 0.5
+debug   = true
+timeout = 10 * 3
+`,
+			expected: map[string]string{
+				"NAME":    "api",
+				"PORT":    "8080",
+				"RATIO":   "0.5",
+				"DEBUG":   "true",
+				"TIMEOUT": "30",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid HCL with blocks and labels",
+			fileContent: `
+server {
+  port = 8080
+}
+
+database "primary" {
+  host = "db1"
+  pool {
+    size = 10
+  }
+}
+
+database "replica" {
+  host = "db2"
+}
+`,
+			expected: map[string]string{
+				"SERVER_PORT":                "8080",
+				"DATABASE_PRIMARY_HOST":      "db1",
+				"DATABASE_PRIMARY_POOL_SIZE": "10",
+				"DATABASE_REPLICA_HOST":      "db2",
+			},
+			expectError: false,
+		},
+		{
+			name: "Repeated blocks are merged recursively",
+			fileContent: `
+server {
+  port = 8080
+  tls {
+    cert = "a"
+  }
+}
+
+server {
+  tls {
+    key = "b"
+  }
+}
+`,
+			expected: map[string]string{
+				"SERVER_PORT":     "8080",
+				"SERVER_TLS_CERT": "a",
+				"SERVER_TLS_KEY":  "b",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid HCL with object attribute",
+			fileContent: `
+limits = {
+  cpu    = "500m"
+  memory = "1Gi"
+}
+`,
+			expected: map[string]string{
+				"LIMITS_CPU":    "500m",
+				"LIMITS_MEMORY": "1Gi",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid HCL with lists and nulls",
+			fileContent: `
+zones   = ["a", "b"]
+backup  = null
+`,
+			expected: map[string]string{
+				"ZONES":   "a,b",
+				"ZONES_0": "a",
+				"ZONES_1": "b",
+			},
+			expectError: false,
+		},
+		{
+			name:        "Variables are not allowed",
+			fileContent: `port = var.port`,
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name:        "Functions are not allowed",
+			fileContent: `name = upper("api")`,
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name:        "Invalid HCL content",
+			fileContent: `name = `,
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name:        "Empty HCL content",
+			fileContent: ``,
+			expected:    map[string]string{},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.CreateTemp("", "test_*.hcl")
+			assert.NoError(t, err, "Failed to create temp file")
+			defer os.Remove(file.Name())
+
+			_, err = file.WriteString(tt.fileContent)
+			assert.NoError(t, err, "Failed to write to temp file")
+
+			file.Close()
+
+			loader := &HCLLoader{}
+			result, err := loader.Load(file.Name())
+
+			if tt.expectError {
+				assert.Error(t, err, "Expected an error but got none")
+			} else {
+				assert.NoError(t, err, "Did not expect an error but got one")
+				assert.Equal(t, tt.expected, result, "Loaded configuration did not match expected")
+			}
+		})
+	}
+}
+
+func TestHCLLoader_LoadReader(t *testing.T) {
+	loader := &HCLLoader{}
+	result, err := loader.LoadReader(strings.NewReader("db {\n  host = \"localhost\"\n}\n"))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"DB_HOST": "localhost"}, result, "Loaded configuration did not match expected")
+}
@@ -0,0 +1,104 @@
+package hcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HCLWriter writes configuration as an HCL2 file
+type HCLWriter struct{}
+
+// Write writes maps as blocks and everything else as attributes, so that `db { port = 5432 }` is read
+// back as DB_PORT. Keys must be valid HCL identifiers
+func (h *HCLWriter) Write(w io.Writer, root *tree.Node) error {
+	file := hclwrite.NewEmptyFile()
+	if err := writeBody(file.Body(), root); err != nil {
+		return err
+	}
+	_, err := file.WriteTo(w)
+	return err
+}
+
+// writeBody adds the children of a map node to body
+func writeBody(body *hclwrite.Body, node *tree.Node) error {
+	for _, key := range node.Keys {
+		if !hclsyntax.ValidIdentifier(key) {
+			return fmt.Errorf("key %q is not a valid HCL identifier", key)
+		}
+		child := node.Children[key]
+		if child.Kind == tree.MapNode {
+			if err := writeBody(body.AppendNewBlock(key, nil).Body(), child); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := encodeValue(child)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		body.SetAttributeValue(key, value)
+	}
+	return nil
+}
+
+// encodeValue converts a tree node into a cty value, the inverse of decodeValue
+func encodeValue(node *tree.Node) (cty.Value, error) {
+	switch node.Kind {
+	case tree.NullNode:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case tree.MapNode:
+		attributes := make(map[string]cty.Value, len(node.Keys))
+		for _, key := range node.Keys {
+			value, err := encodeValue(node.Children[key])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attributes[key] = value
+		}
+		return cty.ObjectVal(attributes), nil
+	case tree.ListNode:
+		if len(node.Items) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		items := make([]cty.Value, 0, len(node.Items))
+		for _, item := range node.Items {
+			value, err := encodeValue(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			items = append(items, value)
+		}
+		return cty.TupleVal(items), nil
+	}
+
+	switch v := node.Value.(type) {
+	case string:
+		return cty.StringVal(v), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case int64:
+		return cty.NumberIntVal(v), nil
+	case uint64:
+		return cty.NumberUIntVal(v), nil
+	case float64:
+		return cty.NumberFloatVal(v), nil
+	case json.Number:
+		number, _, err := big.ParseFloat(v.String(), 10, 512, big.ToNearestEven)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.NumberVal(number), nil
+	case time.Time:
+		return cty.StringVal(v.Format(time.RFC3339Nano)), nil
+	default:
+		return cty.StringVal(node.String()), nil
+	}
+}
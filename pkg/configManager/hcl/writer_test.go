@@ -0,0 +1,16 @@
+package hcl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHCLWriter_Unrepresentable(t *testing.T) {
+	root, err := tree.FromValue(map[string]interface{}{"db": map[string]interface{}{"max size": 1}})
+	assert.NoError(t, err, "Did not expect an error but got one")
+	err = (&HCLWriter{}).Write(&bytes.Buffer{}, root)
+	assert.EqualError(t, err, `key "max size" is not a valid HCL identifier`)
+}
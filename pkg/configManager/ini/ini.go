@@ -0,0 +1,90 @@
+package ini
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/keys"
+)
+
+// INILoader implements ConfigLoader for .ini, .cfg and .conf files
+type INILoader struct {
+	normalizer keys.Normalizer
+}
+
+// SetKeyNormalizer sets the normalizer applied to section and key names. Names are uppercased and joined
+// with '_' when none is set
+func (i *INILoader) SetKeyNormalizer(normalizer keys.Normalizer) {
+	i.normalizer = normalizer
+}
+
+// Load parses INI files and returns key-value pairs, mapping keys inside a section to SECTION_KEY
+func (i *INILoader) Load(filePath string) (map[string]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return i.LoadReader(file)
+}
+
+// LoadReader parses INI content from a reader and returns key-value pairs
+func (i *INILoader) LoadReader(r io.Reader) (map[string]string, error) {
+	normalizer := i.normalizer
+	if normalizer == nil {
+		normalizer = keys.Upper
+	}
+
+	configs := internal.NewCollector(normalizer)
+	var section []string
+	lineNumber := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: malformed section header %q", lineNumber, line)
+			}
+			section = strings.FieldsFunc(line[1:len(line)-1], func(r rune) bool {
+				return r == '.' || unicode.IsSpace(r)
+			})
+			continue
+		}
+
+		separator := strings.IndexAny(line, "=:")
+		if separator == -1 {
+			return nil, fmt.Errorf("line %d: missing '=' or ':' in %q", lineNumber, line)
+		}
+		key := strings.TrimSpace(line[:separator])
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNumber)
+		}
+		path := append(append([]string(nil), section...), strings.FieldsFunc(key, unicode.IsSpace)...)
+		if err := configs.Add(path, unquote(strings.TrimSpace(line[separator+1:]))); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+	}
+	return configs.Result(), scanner.Err()
+}
+
+// unquote strips a matching pair of single or double quotes surrounding a value
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
@@ -0,0 +1,113 @@
+package ini
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestINILoader_Load(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileContent string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "Valid INI with global keys",
+			fileContent: `
+key1=value1
+key2 = value2
+`,
+			expected: map[string]string{
+				"KEY1": "value1",
+				"KEY2": "value2",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid INI with sections",
+			fileContent: `
+[database]
+host = localhost
+port: 5432
+
+[server.http]
+port=8080
+`,
+			expected: map[string]string{
+				"DATABASE_HOST":    "localhost",
+				"DATABASE_PORT":    "5432",
+				"SERVER_HTTP_PORT": "8080",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid INI with comments and quoted values",
+			fileContent: `
+; semicolon comment
+# hash comment
+[app]
+name = "my app"
+motto = 'keep it simple'
+url = http://localhost/#anchor
+`,
+			expected: map[string]string{
+				"APP_NAME":  "my app",
+				"APP_MOTTO": "keep it simple",
+				"APP_URL":   "http://localhost/#anchor",
+			},
+			expectError: false,
+		},
+		{
+			name:        "Malformed section header",
+			fileContent: "[database\nhost=localhost\n",
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name:        "Entry without separator",
+			fileContent: "[database]\nhost\n",
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name:        "Empty INI content",
+			fileContent: ``,
+			expected:    map[string]string{},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.CreateTemp("", "test_*.ini")
+			assert.NoError(t, err, "Failed to create temp file")
+			defer os.Remove(file.Name())
+
+			_, err = file.WriteString(tt.fileContent)
+			assert.NoError(t, err, "Failed to write to temp file")
+
+			file.Close()
+
+			loader := &INILoader{}
+			result, err := loader.Load(file.Name())
+
+			if tt.expectError {
+				assert.Error(t, err, "Expected an error but got none")
+			} else {
+				assert.NoError(t, err, "Did not expect an error but got one")
+				assert.Equal(t, tt.expected, result, "Loaded configuration did not match expected")
+			}
+		})
+	}
+}
+
+func TestINILoader_LoadReader(t *testing.T) {
+	loader := &INILoader{}
+	result, err := loader.LoadReader(strings.NewReader("[db]\nhost = localhost\n"))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"DB_HOST": "localhost"}, result, "Loaded configuration did not match expected")
+}
@@ -0,0 +1,104 @@
+package ini
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// INIWriter writes configuration as an INI file
+type INIWriter struct{}
+
+// Write writes the scalars of each map as key = value lines under a section named by the dotted path of
+// the map, starting with the top-level scalars outside of any section. Lists of scalars are written
+// comma-joined, and the maps in a list get one section per index. Nulls are left out, as they are from the
+// flat view, and lists that mix maps with other values or hold lists cannot be written
+func (i *INIWriter) Write(w io.Writer, root *tree.Node) error {
+	out := bufio.NewWriter(w)
+	if err := writeSection(out, nil, root, true); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// writeSection writes the scalars of node under the section path and then its nested sections
+func writeSection(out *bufio.Writer, path []string, node *tree.Node, first bool) error {
+	var nested []string
+	wroteHeader := len(path) == 0
+	for _, key := range node.Keys {
+		child := node.Children[key]
+		if child.Kind == tree.NullNode {
+			continue
+		}
+		value, isScalar := iniValue(child)
+		if !isScalar {
+			nested = append(nested, key)
+			continue
+		}
+		if strings.ContainsAny(key, "=:\r\n") || strings.TrimSpace(key) != key {
+			return fmt.Errorf("key %q cannot be written to an INI file", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("value of %s spans several lines, which INI files cannot represent", key)
+		}
+		if !wroteHeader {
+			if !first {
+				out.WriteString("\n")
+			}
+			fmt.Fprintf(out, "[%s]\n", strings.Join(path, "."))
+			wroteHeader, first = true, false
+		}
+		fmt.Fprintf(out, "%s = %s\n", key, value)
+		first = false
+	}
+
+	for _, key := range nested {
+		child := node.Children[key]
+		if child.Kind == tree.MapNode {
+			if err := writeSection(out, appendPath(path, key), child, first && !wroteHeader); err != nil {
+				return err
+			}
+			continue
+		}
+		for index, item := range child.Items {
+			if item.Kind != tree.MapNode {
+				return fmt.Errorf("%s mixes maps with other values or holds lists, which INI files cannot represent", strings.Join(appendPath(path, key), "."))
+			}
+			if err := writeSection(out, appendPath(path, key, strconv.Itoa(index)), item, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// iniValue formats a scalar, null or list of scalars as an INI value, quoting values that would
+// otherwise lose surrounding whitespace or quotes
+func iniValue(node *tree.Node) (string, bool) {
+	var value string
+	switch node.Kind {
+	case tree.MapNode:
+		return "", false
+	case tree.ListNode:
+		joined, ok := node.Joined()
+		if !ok {
+			return "", false
+		}
+		value = joined
+	default:
+		value = node.String()
+	}
+
+	if strings.TrimSpace(value) != value || unquote(value) != value {
+		value = `"` + value + `"`
+	}
+	return value, true
+}
+
+func appendPath(path []string, segments ...string) []string {
+	return append(append([]string(nil), path...), segments...)
+}
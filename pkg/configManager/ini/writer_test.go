@@ -0,0 +1,31 @@
+package ini
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestINIWriter_Unrepresentable(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		err   string
+	}{
+		{name: "List mixing maps and scalars", value: map[string]interface{}{"hosts": []interface{}{"a", map[string]interface{}{"x": 1}}}, err: "hosts mixes maps with other values or holds lists, which INI files cannot represent"},
+		{name: "List of lists", value: map[string]interface{}{"db": map[string]interface{}{"m": []interface{}{[]interface{}{1, 2}, []interface{}{3}}}}, err: "db.m mixes maps with other values or holds lists, which INI files cannot represent"},
+		{name: "Several lines", value: map[string]interface{}{"cert": "line one\nline two"}, err: "value of cert spans several lines, which INI files cannot represent"},
+		{name: "Key with a separator", value: map[string]interface{}{"a=b": "c"}, err: `key "a=b" cannot be written to an INI file`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := tree.FromValue(tt.value)
+			assert.NoError(t, err, "Did not expect an error but got one")
+			err = (&INIWriter{}).Write(&bytes.Buffer{}, root)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -1,10 +1,62 @@
 package configManager
 
+import (
+	"io"
+	"io/fs"
+
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
 // ConfigManager is the interface for loading configuration files
 type ConfigManager interface {
 	Load(filePath string) (map[string]string, error)
 }
 
+// ReaderLoader is implemented by loaders that can also parse configuration from an io.Reader, which is
+// required to read files from an fs.FS
+type ReaderLoader interface {
+	LoadReader(r io.Reader) (map[string]string, error)
+}
+
+// TreeLoader is implemented by loaders that can return the parsed document as a tree, keeping its
+// structure, value types and source positions. Config derives the flat key-value view from the tree and
+// uses the tree to bind lists and nested structures in Unmarshal
+type TreeLoader interface {
+	LoadTree(r io.Reader, fileName string) (*tree.Node, error)
+}
+
+// ConfigWriter is the interface for writing configuration files. Loading the output of a writer with the
+// loader of the same format yields the same keys and values; values the format cannot represent are
+// reported as errors rather than dropped
+type ConfigWriter interface {
+	Write(w io.Writer, root *tree.Node) error
+}
+
+// FSLoader is implemented by loaders that read files themselves, for example to follow include directives.
+// Config passes the file system it reads from before loading a file, nil meaning the local disk, and then
+// calls Load (or LoadTree) with a path in that file system
+type FSLoader interface {
+	SetFS(fsys fs.FS)
+}
+
+// SchemaLoader is implemented by loaders that can validate the decoded document against a JSON Schema
+// before flattening it. Config passes the schema attached with WithSchema or WithLayerSchema, or nil,
+// before loading a file
+type SchemaLoader interface {
+	SetSchema(schema *jsonschema.Schema)
+}
+
+// KeyNormalizer converts key paths into flat configuration keys. See package keys for the built-in
+// strategies
+type KeyNormalizer = keys.Normalizer
+
+// KeyNormalizingLoader is implemented by loaders that can apply a KeyNormalizer to the keys they return
+type KeyNormalizingLoader interface {
+	SetKeyNormalizer(normalizer keys.Normalizer)
+}
+
 // CacheManager is the interface for managing in-memory cache
 type CacheManager interface {
 	Get(key string) (string, bool)
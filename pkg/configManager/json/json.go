@@ -1,26 +1,170 @@
 package json
 
 import (
-	"encoding/json"
-	"io/ioutil"
+	"io"
+	"io/fs"
 
 	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
 )
 
 // JSONLoader implements ConfigLoader for .json files
-type JSONLoader struct{}
+type JSONLoader struct {
+	// AllowComments accepts // and /* */ comments and trailing commas, as used by .jsonc and .json5 files
+	AllowComments bool
 
-// Load parses JSON files and returns key-value pairs
+	normalizer keys.Normalizer
+	fsys       fs.FS
+	schema     *jsonschema.Schema
+}
+
+// SetKeyNormalizer sets the normalizer applied to key paths. Keys are uppercased and joined with '_' when
+// none is set
+func (j *JSONLoader) SetKeyNormalizer(normalizer keys.Normalizer) {
+	j.normalizer = normalizer
+}
+
+// SetFS makes Load and include directives read files from fsys instead of the local disk
+func (j *JSONLoader) SetFS(fsys fs.FS) {
+	j.fsys = fsys
+}
+
+// SetSchema makes Load, LoadReader and LoadTree validate the decoded document, after includes are merged,
+// against schema. Nil disables validation
+func (j *JSONLoader) SetSchema(schema *jsonschema.Schema) {
+	j.schema = schema
+}
+
+// Load parses JSON files and returns key-value pairs. A top-level "include" or "$import" key pulls in
+// other files, see LoadTree
 func (j *JSONLoader) Load(filePath string) (map[string]string, error) {
-	content, err := ioutil.ReadFile(filePath)
+	content, err := internal.FilesFor(j.fsys).ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	var data map[string]interface{}
-	err = json.Unmarshal(content, &data)
+
+	return j.parse(content, filePath)
+}
+
+// LoadReader parses JSON content from a reader and returns key-value pairs
+func (j *JSONLoader) LoadReader(r io.Reader) (map[string]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.parse(content, "")
+}
+
+// LoadTree parses JSON content from a reader into a tree that keeps value types and source positions.
+// Files named by a top-level "include" or "$import" key, relative to fileName and possibly glob patterns,
+// are merged in declared order underneath the content of the file itself
+func (j *JSONLoader) LoadTree(r io.Reader, fileName string) (*tree.Node, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.decode(content, fileName)
+}
+
+// decode parses JSON content into a tree and resolves its include directives. The result is validated against
+// the schema, if one is set
+func (j *JSONLoader) decode(content []byte, fileName string) (*tree.Node, error) {
+	root, err := j.decodeFile(content, fileName)
 	if err != nil {
 		return nil, err
 	}
 
-	return internal.FlattenMap(data, ""), nil
+	root, err = internal.ResolveIncludes(root, fileName, internal.FilesFor(j.fsys), j.decodeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.schema != nil {
+		if err := j.schema.Validate(root); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// decodeFile parses the JSON content of a single file into a tree
+func (j *JSONLoader) decodeFile(content []byte, fileName string) (*tree.Node, error) {
+	if j.AllowComments {
+		content = stripComments(content)
+	}
+	return decodeTree(content, fileName)
+}
+
+// parse decodes JSON content and flattens it into key-value pairs
+func (j *JSONLoader) parse(content []byte, fileName string) (map[string]string, error) {
+	root, err := j.decode(content, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := j.normalizer
+	if normalizer == nil {
+		normalizer = keys.Upper
+	}
+
+	configs, _, err := internal.FlattenTree(root, normalizer)
+	return configs, err
+}
+
+// stripComments blanks out // and /* */ comments and trailing commas outside of strings. Removed bytes
+// are replaced with spaces so that offsets reported by the JSON decoder still point at the original text
+func stripComments(content []byte) []byte {
+	result := make([]byte, len(content))
+	copy(result, content)
+
+	inString := false
+	lastComma := -1
+	for i := 0; i < len(result); i++ {
+		c := result[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			lastComma = -1
+		case c == '/' && i+1 < len(result) && result[i+1] == '/':
+			for ; i < len(result) && result[i] != '\n'; i++ {
+				result[i] = ' '
+			}
+		case c == '/' && i+1 < len(result) && result[i+1] == '*':
+			result[i], result[i+1] = ' ', ' '
+			for i += 2; i < len(result) && !(result[i] == '*' && i+1 < len(result) && result[i+1] == '/'); i++ {
+				if result[i] != '\n' {
+					result[i] = ' '
+				}
+			}
+			if i < len(result) {
+				result[i], result[i+1] = ' ', ' '
+				i++
+			}
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma != -1 {
+				result[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+
+	return result
 }
@@ -0,0 +1,119 @@
+package json
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLoader_Load(t *testing.T) {
+	tests := []struct {
+		name          string
+		fileContent   string
+		allowComments bool
+		expected      map[string]string
+		expectError   bool
+	}{
+		{
+			name:        "Valid JSON with nested structure",
+			fileContent: `{"db": {"url": "postgres://localhost"}, "port": 8080}`,
+			expected: map[string]string{
+				"DB_URL": "postgres://localhost",
+				"PORT":   "8080",
+			},
+			expectError: false,
+		},
+		{
+			name:        "Comments rejected in strict mode",
+			fileContent: "{\n  // comment\n  \"key\": \"value\"\n}",
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name: "Comments and trailing commas in relaxed mode",
+			fileContent: `{
+  // line comment
+  "key": "value", /* block
+  comment */
+  "url": "http://example.com/*not-a-comment*/",
+  "nested": {"a": "1",},
+}`,
+			allowComments: true,
+			expected: map[string]string{
+				"KEY":      "value",
+				"URL":      "http://example.com/*not-a-comment*/",
+				"NESTED_A": "1",
+			},
+			expectError: false,
+		},
+		{
+			name:          "Invalid JSON in relaxed mode",
+			fileContent:   `{"key": }`,
+			allowComments: true,
+			expected:      nil,
+			expectError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.CreateTemp("", "test_*.json")
+			assert.NoError(t, err, "Failed to create temp file")
+			defer os.Remove(file.Name())
+
+			_, err = file.WriteString(tt.fileContent)
+			assert.NoError(t, err, "Failed to write to temp file")
+
+			file.Close()
+
+			loader := &JSONLoader{AllowComments: tt.allowComments}
+			result, err := loader.Load(file.Name())
+
+			if tt.expectError {
+				assert.Error(t, err, "Expected an error but got none")
+			} else {
+				assert.NoError(t, err, "Did not expect an error but got one")
+				assert.Equal(t, tt.expected, result, "Loaded configuration did not match expected")
+			}
+		})
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	input := "{\"a\": \"// kept\", // dropped\n\"b\": [1, 2,],}"
+	expected := "{\"a\": \"// kept\",           \n\"b\": [1, 2 ] }"
+	assert.Equal(t, expected, string(stripComments([]byte(input))), "stripComments should preserve offsets")
+}
+
+func TestJSONLoader_LoadReader(t *testing.T) {
+	loader := &JSONLoader{}
+	result, err := loader.LoadReader(strings.NewReader(`{"db": {"host": "localhost"}}`))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"DB_HOST": "localhost"}, result, "Loaded configuration did not match expected")
+}
+
+func TestJSONLoader_LoadTree(t *testing.T) {
+	loader := &JSONLoader{}
+	root, err := loader.LoadTree(strings.NewReader("{\n  \"servers\": [\n    {\"port\": 8080, \"ratio\": 0.5}\n  ]\n}"), "config.json")
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	server := root.Children["servers"].Items[0]
+	assert.Equal(t, int64(8080), server.Children["port"].Value, "integers should be decoded as int64")
+	assert.Equal(t, 0.5, server.Children["ratio"].Value, "decimals should be decoded as float64")
+	assert.Equal(t, "config.json:3:14", server.Children["port"].Pos.String(), "nodes should carry their position")
+
+	_, err = loader.LoadTree(strings.NewReader("{\n  \"a\": ,\n}"), "broken.json")
+	assert.ErrorContains(t, err, "broken.json:2", "syntax errors should carry their position")
+}
+
+func TestJSONLoader_SetSchema(t *testing.T) {
+	loader := &JSONLoader{}
+	loader.SetSchema(jsonschema.MustCompile([]byte(`{"required": ["name"], "properties": {"servers": {"items": {"type": "string"}}}}`)))
+
+	_, err := loader.LoadTree(strings.NewReader("{\n  \"servers\": [\"a\", 1]\n}"), "config.json")
+	assert.EqualError(t, err, "config.json:1:1: (root): missing required property \"name\"\n"+
+		"config.json:2:20: /servers/1: expected string, got integer", "every error should be reported with its position")
+}
@@ -0,0 +1,156 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// treeDecoder builds a tree.Node tree from JSON tokens, tracking the line and column of every value
+type treeDecoder struct {
+	decoder    *json.Decoder
+	content    []byte
+	lineStarts []int
+	fileName   string
+}
+
+// decodeTree decodes a JSON document whose top level must be an object (or null, which yields an empty tree)
+func decodeTree(content []byte, fileName string) (*tree.Node, error) {
+	d := &treeDecoder{
+		decoder:    json.NewDecoder(bytes.NewReader(content)),
+		content:    content,
+		lineStarts: []int{0},
+		fileName:   fileName,
+	}
+	d.decoder.UseNumber()
+	for i, c := range content {
+		if c == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+
+	root, err := d.value()
+	if err != nil {
+		return nil, d.wrap(err)
+	}
+	if _, err := d.decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%s: unexpected data after top-level value", d.position(d.next()))
+	}
+
+	switch root.Kind {
+	case tree.NullNode:
+		return tree.NewMap(root.Pos), nil
+	case tree.MapNode:
+		return root, nil
+	default:
+		return nil, fmt.Errorf("%s: configuration must be an object at the top level", root.Pos)
+	}
+}
+
+// value decodes the next value, recursing into objects and arrays
+func (d *treeDecoder) value() (*tree.Node, error) {
+	pos := d.position(d.next())
+	token, err := d.decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			node := tree.NewMap(pos)
+			for d.decoder.More() {
+				keyToken, err := d.decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				child, err := d.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Set(keyToken.(string), child)
+			}
+			_, err := d.decoder.Token()
+			return node, err
+		}
+		node := tree.NewList(pos)
+		for d.decoder.More() {
+			item, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			node.Items = append(node.Items, item)
+		}
+		_, err := d.decoder.Token()
+		return node, err
+	case json.Number:
+		return tree.NewScalar(typedNumber(t), pos), nil
+	default:
+		return tree.NewScalar(t, pos), nil
+	}
+}
+
+// typedNumber decodes integers as int64 or uint64 and fractions as float64. Numbers that do not fit are kept
+// as json.Number so that their text is preserved exactly
+func typedNumber(number json.Number) interface{} {
+	if integer, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+		return integer
+	}
+	if unsigned, err := strconv.ParseUint(string(number), 10, 64); err == nil {
+		return unsigned
+	}
+	if strings.ContainsAny(string(number), ".eE") {
+		if float, err := number.Float64(); err == nil {
+			return float
+		}
+	}
+	return number
+}
+
+// next returns the offset of the next value, skipping whitespace and the separators the decoder consumes
+// implicitly
+func (d *treeDecoder) next() int {
+	offset := int(d.decoder.InputOffset())
+	for offset < len(d.content) {
+		switch d.content[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// position converts a byte offset into a line and column
+func (d *treeDecoder) position(offset int) tree.Position {
+	line := sort.SearchInts(d.lineStarts, offset+1)
+	return tree.Position{File: d.fileName, Line: line, Column: offset - d.lineStarts[line-1] + 1}
+}
+
+// wrap prefixes syntax and type errors with the position they occurred at
+func (d *treeDecoder) wrap(err error) error {
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		// Offset counts the bytes read, including the one that caused the error
+		offset := int(syntaxError.Offset)
+		if offset > 0 {
+			offset--
+		}
+		return fmt.Errorf("%s: %v", d.position(offset), err)
+	}
+	if err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%s: unexpected end of JSON input", d.position(len(d.content)))
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// JSONWriter writes configuration as an indented JSON document
+type JSONWriter struct{}
+
+// Write encodes the tree as JSON, keeping the order of keys and the types of values
+func (j *JSONWriter) Write(w io.Writer, root *tree.Node) error {
+	var b bytes.Buffer
+	if err := writeNode(&b, root, ""); err != nil {
+		return err
+	}
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeNode appends the JSON encoding of node, indenting nested lines by indent
+func writeNode(b *bytes.Buffer, node *tree.Node, indent string) error {
+	nested := indent + "  "
+	switch node.Kind {
+	case tree.MapNode:
+		if len(node.Keys) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteString("{\n")
+		for i, key := range node.Keys {
+			name, _ := json.Marshal(key)
+			b.WriteString(nested)
+			b.Write(name)
+			b.WriteString(": ")
+			if err := writeNode(b, node.Children[key], nested); err != nil {
+				return err
+			}
+			writeSeparator(b, i, len(node.Keys))
+		}
+		b.WriteString(indent + "}")
+	case tree.ListNode:
+		if len(node.Items) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteString("[\n")
+		for i, item := range node.Items {
+			b.WriteString(nested)
+			if err := writeNode(b, item, nested); err != nil {
+				return err
+			}
+			writeSeparator(b, i, len(node.Items))
+		}
+		b.WriteString(indent + "]")
+	case tree.NullNode:
+		b.WriteString("null")
+	default:
+		value, err := json.Marshal(node.Value)
+		if err != nil {
+			return err
+		}
+		b.Write(value)
+	}
+	return nil
+}
+
+// writeSeparator ends the i-th of n members of an object or array
+func writeSeparator(b *bytes.Buffer, i, n int) {
+	if i < n-1 {
+		b.WriteString(",")
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,25 @@
+package json
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONWriter_KeepsOrderAndTypes(t *testing.T) {
+	root := tree.NewMap(tree.Position{})
+	root.Set("name", tree.NewScalar("app", tree.Position{}))
+	root.Set("port", tree.NewScalar(int64(8080), tree.Position{}))
+	root.Set("backup", tree.NewScalar(nil, tree.Position{}))
+	root.Set("version", tree.NewScalar("1.0", tree.Position{}))
+	flags := tree.NewList(tree.Position{})
+	flags.Items = append(flags.Items, tree.NewScalar(true, tree.Position{}))
+	root.Set("flags", flags)
+
+	var out bytes.Buffer
+	err := (&JSONWriter{}).Write(&out, root)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, "{\n  \"name\": \"app\",\n  \"port\": 8080,\n  \"backup\": null,\n  \"version\": \"1.0\",\n  \"flags\": [\n    true\n  ]\n}\n", out.String(), "keys should keep their order and scalars their types")
+}
@@ -1,24 +1,142 @@
 package configManager
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/LetsFocus/configManager/pkg/configManager/env"
+	"github.com/LetsFocus/configManager/pkg/configManager/hcl"
+	"github.com/LetsFocus/configManager/pkg/configManager/ini"
 	"github.com/LetsFocus/configManager/pkg/configManager/json"
+	"github.com/LetsFocus/configManager/pkg/configManager/properties"
 	"github.com/LetsFocus/configManager/pkg/configManager/yaml"
 )
 
-// LoaderFactory creates ConfigLoader instances based on file extensions
+var (
+	yamlLinePattern = regexp.MustCompile(`^[\w.-]+\s*:(\s|$)`)
+	envLinePattern  = regexp.MustCompile(`^[\w.]+\s*=`)
+	hclBlockPattern = regexp.MustCompile(`^\w+(\s+"[^"]*")*\s*\{$`)
+
+	hclAttributePattern = regexp.MustCompile(`^[A-Za-z_][\w-]*\s*=\s*(.*)$`)
+	hclStringPattern    = regexp.MustCompile(`^"([^"\\]|\\.)*"$`)
+	hclLiteralPattern   = regexp.MustCompile(`^(-?\d+(\.\d+)?|true|false|null)$`)
+)
+
+// Built-in loaders, registered in discovery priority order: .env > .json > .yaml > .yml, followed by the
+// formats added later so that existing setups keep picking the same file
+func init() {
+	RegisterLoader([]string{".env"}, func() ConfigManager { return &env.EnvLoader{} },
+		WithPriority(700), WithMIMETypes("text/x-env", "application/x-env"))
+	RegisterLoader([]string{".json"}, func() ConfigManager { return &json.JSONLoader{} },
+		WithPriority(600), WithMIMETypes("application/json", "text/json"))
+	RegisterLoader([]string{".yaml", ".yml"}, func() ConfigManager { return &yaml.YAMLLoader{} },
+		WithPriority(500), WithMIMETypes("application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"))
+	RegisterLoader([]string{".jsonc", ".json5"}, func() ConfigManager { return &json.JSONLoader{AllowComments: true} },
+		WithPriority(400), WithMIMETypes("application/jsonc", "application/json5"))
+	RegisterLoader([]string{".hcl"}, func() ConfigManager { return &hcl.HCLLoader{} },
+		WithPriority(300), WithMIMETypes("application/hcl", "text/x-hcl"))
+	RegisterLoader([]string{".ini", ".cfg", ".conf"}, func() ConfigManager { return &ini.INILoader{} },
+		WithPriority(200), WithMIMETypes("text/x-ini", "application/x-ini"))
+	RegisterLoader([]string{".properties"}, func() ConfigManager { return &properties.PropertiesLoader{} },
+		WithPriority(100), WithMIMETypes("text/x-java-properties"))
+}
+
+// LoaderFactory creates ConfigLoader instances based on file extensions registered with RegisterLoader.
+// Files without an extension are identified by their content
 func LoaderFactory(filePath string) (ConfigManager, error) {
-	switch {
-	case strings.HasSuffix(filePath, ".env"):
-		return &env.EnvLoader{}, nil
-	case strings.HasSuffix(filePath, ".yaml"):
-		return &yaml.YAMLLoader{}, nil
-	case strings.HasSuffix(filePath, ".json"):
-		return &json.JSONLoader{}, nil
-	default:
-		return nil, fmt.Errorf("unsupported file type: %s", filePath)
+	return defaultLoaders.loaderFor(filePath, os.ReadFile)
+}
+
+// loaderFor resolves the loader for a file from the registry, falling back to content detection for
+// files without an extension, which are read with readFile
+func (r *loaderRegistry) loaderFor(filePath string, readFile func(string) ([]byte, error)) (ConfigManager, error) {
+	if loader, found := r.forFile(filePath); found {
+		return loader, nil
+	}
+
+	if filePath != "" && filepath.Ext(filePath) == "" {
+		content, err := readFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported file type: %s", filePath)
+		}
+		loader, err := DetectLoader(content)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported file type: %s: %v", filePath, err)
+		}
+		return loader, nil
+	}
+
+	return nil, fmt.Errorf("unsupported file type: %s", filePath)
+}
+
+// DetectLoader sniffs the content of a configuration file and returns a matching loader. It looks at the
+// first line that is not blank or a '#'/';' comment, and past it when that line could be either a .env
+// assignment or an HCL attribute
+func DetectLoader(content []byte) (ConfigManager, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "{"), strings.HasPrefix(line, "//"), strings.HasPrefix(line, "/*"):
+			return &json.JSONLoader{AllowComments: true}, nil
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			return &ini.INILoader{}, nil
+		case line == "---", yamlLinePattern.MatchString(line):
+			return &yaml.YAMLLoader{}, nil
+		case envLinePattern.MatchString(line):
+			if looksLikeHCL(line, scanner) {
+				return &hcl.HCLLoader{}, nil
+			}
+			return &env.EnvLoader{}, nil
+		case hclBlockPattern.MatchString(line):
+			return &hcl.HCLLoader{}, nil
+		default:
+			return nil, fmt.Errorf("unrecognized content %q", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("no content to detect")
+}
+
+// looksLikeHCL reads the rest of content that starts with an assignment and reports whether it is HCL
+// rather than .env: it has blocks, '//' comments or list and object values, or only attributes with
+// literal values of which at least one is a quoted string. A value that HCL cannot parse, such as an
+// unquoted word, makes it .env
+func looksLikeHCL(line string, scanner *bufio.Scanner) bool {
+	quoted := false
+	for {
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case hclBlockPattern.MatchString(line), line == "}", strings.HasPrefix(line, "//"), strings.HasPrefix(line, "/*"):
+			return true
+		default:
+			match := hclAttributePattern.FindStringSubmatch(line)
+			if match == nil {
+				return false
+			}
+			value := match[1]
+			switch {
+			case strings.HasPrefix(value, "["), strings.HasPrefix(value, "{"), strings.HasPrefix(value, "<<"):
+				return true
+			case hclStringPattern.MatchString(value):
+				quoted = true
+			case !hclLiteralPattern.MatchString(value):
+				return false
+			}
+		}
+		if !scanner.Scan() {
+			return quoted
+		}
+		line = strings.TrimSpace(scanner.Text())
 	}
 }
@@ -2,10 +2,15 @@ package configManager
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/LetsFocus/configManager/pkg/configManager/env"
+	"github.com/LetsFocus/configManager/pkg/configManager/hcl"
+	"github.com/LetsFocus/configManager/pkg/configManager/ini"
 	"github.com/LetsFocus/configManager/pkg/configManager/json"
+	"github.com/LetsFocus/configManager/pkg/configManager/properties"
 	"github.com/LetsFocus/configManager/pkg/configManager/yaml"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,12 +34,60 @@ func TestLoaderFactory(t *testing.T) {
 			expectedType:  &yaml.YAMLLoader{},
 			expectedError: nil,
 		},
+		{
+			name:          "Valid .yml file",
+			filePath:      "config.yml",
+			expectedType:  &yaml.YAMLLoader{},
+			expectedError: nil,
+		},
 		{
 			name:          "Valid .json file",
 			filePath:      "config.json",
 			expectedType:  &json.JSONLoader{},
 			expectedError: nil,
 		},
+		{
+			name:          "Valid .jsonc file",
+			filePath:      "config.jsonc",
+			expectedType:  &json.JSONLoader{},
+			expectedError: nil,
+		},
+		{
+			name:          "Valid .json5 file",
+			filePath:      "config.json5",
+			expectedType:  &json.JSONLoader{},
+			expectedError: nil,
+		},
+		{
+			name:          "Valid .ini file",
+			filePath:      "config.ini",
+			expectedType:  &ini.INILoader{},
+			expectedError: nil,
+		},
+		{
+			name:          "Valid .cfg file",
+			filePath:      "config.cfg",
+			expectedType:  &ini.INILoader{},
+			expectedError: nil,
+		},
+		{
+			name:          "Valid .conf file",
+			filePath:      "config.conf",
+			expectedType:  &ini.INILoader{},
+			expectedError: nil,
+		},
+		{
+			name:          "Valid .properties file",
+			filePath:      "config.properties",
+			expectedType:  &properties.PropertiesLoader{},
+			expectedError: nil,
+		},
+		{
+			name:          "Valid .hcl file",
+			filePath:      "config.hcl",
+			expectedType:  &hcl.HCLLoader{},
+			expectedError: nil,
+		},
 		{
 			name:          "Unsupported file type",
 			filePath:      "config.txt",
@@ -47,6 +100,12 @@ func TestLoaderFactory(t *testing.T) {
 			expectedType:  nil,
 			expectedError: errors.New("unsupported file type: "),
 		},
+		{
+			name:          "Missing file without extension",
+			filePath:      "nonexistent",
+			expectedType:  nil,
+			expectedError: errors.New("unsupported file type: nonexistent"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -69,3 +128,65 @@ func TestLoaderFactory(t *testing.T) {
 		})
 	}
 }
+
+func TestLoaderFactory_DetectsContentWithoutExtension(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(filePath, []byte("# settings\nkey: value\n"), 0644)
+
+	loader, err := LoaderFactory(filePath)
+	assert.NoError(t, err, "LoaderFactory should detect the file content")
+	assert.IsType(t, &yaml.YAMLLoader{}, loader, "loader type mismatch")
+
+	configs, err := loader.Load(filePath)
+	assert.NoError(t, err, "detected loader should load the file")
+	assert.Equal(t, map[string]string{"KEY": "value"}, configs, "detected loader returned unexpected configs")
+}
+
+func TestLoaderFactory_DetectsHCLWithoutExtension(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(filePath, []byte("port = 8080\nname = \"svc\"\n\ndb {\n  host = \"localhost\"\n}\n"), 0644)
+
+	loader, err := LoaderFactory(filePath)
+	assert.NoError(t, err, "LoaderFactory should detect the file content")
+	assert.IsType(t, &hcl.HCLLoader{}, loader, "a file starting with an attribute should be detected as HCL")
+
+	configs, err := loader.Load(filePath)
+	assert.NoError(t, err, "detected loader should load the file")
+	assert.Equal(t, map[string]string{"PORT": "8080", "NAME": "svc", "DB_HOST": "localhost"}, configs, "detected loader returned unexpected configs")
+}
+
+func TestDetectLoader(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		expectedType interface{}
+		expectError  bool
+	}{
+		{name: "JSON object", content: `{"key": "value"}`, expectedType: &json.JSONLoader{}},
+		{name: "JSON with leading comment", content: "// settings\n{}", expectedType: &json.JSONLoader{}},
+		{name: "YAML mapping", content: "key: value", expectedType: &yaml.YAMLLoader{}},
+		{name: "YAML document marker", content: "---\nkey: value", expectedType: &yaml.YAMLLoader{}},
+		{name: "INI section", content: "; comment\n[db]\nurl=x", expectedType: &ini.INILoader{}},
+		{name: "Env assignment", content: "# comment\nKEY=value", expectedType: &env.EnvLoader{}},
+		{name: "HCL block", content: `server "web" {`, expectedType: &hcl.HCLLoader{}},
+		{name: "HCL attributes", content: "port = 8080\nname = \"svc\"", expectedType: &hcl.HCLLoader{}},
+		{name: "HCL attribute before a block", content: "port = 8080\ndb {\n  host = \"x\"\n}", expectedType: &hcl.HCLLoader{}},
+		{name: "Env assignments with literal values", content: "PORT=8080\nDEBUG=true", expectedType: &env.EnvLoader{}},
+		{name: "Env assignments with a quoted value", content: "NAME=\"svc\"\nHOST=localhost", expectedType: &env.EnvLoader{}},
+		{name: "Unrecognized content", content: "just some text", expectError: true},
+		{name: "Only comments", content: "# nothing here", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader, err := DetectLoader([]byte(tt.content))
+			if tt.expectError {
+				assert.Error(t, err, "expected an error")
+				assert.Nil(t, loader, "expected loader to be nil")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+				assert.IsType(t, tt.expectedType, loader, "loader type mismatch")
+			}
+		})
+	}
+}
@@ -1,129 +1,426 @@
 package configManager
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 
+	"github.com/LetsFocus/configManager/internal"
 	"github.com/LetsFocus/configManager/pkg/cache"
 )
 
+// dropInDir is the directory inside the base path that WithConfD loads drop-in files from
+const dropInDir = "conf.d"
+
 // Config manages loading and caching configurations
 type Config struct {
-	cache CacheManager
+	cache           CacheManager
+	loaders         *loaderRegistry
+	fsys            fs.FS
+	defaults        fs.FS
+	origins         *provenance
+	nodes           *nodeIndex
+	watchers        *listeners
+	flags           *flagOverrides
+	normalizer      KeyNormalizer
+	confD           bool
+	basePath        string
+	logOutput       io.Writer
+	schemas         []schemaBinding
+	aliases         *aliasTable
+	secretFileLimit int64
+	keyFile         string
+	keyEnv          string
+	prefix          string
 }
 
 // New initializes a new ConfigManager instance
-func New() *Config {
+func New(opts ...Option) *Config {
 	memoryCache := cache.NewInMemoryCache()
-	configManager := &Config{cache: memoryCache}
-	basePath := "./configs"
+	configManager := &Config{
+		cache:    memoryCache,
+		loaders:  newLoaderRegistry(defaultLoaders),
+		origins:  newProvenance(),
+		nodes:    newNodeIndex(),
+		watchers: &listeners{},
+		flags:    &flagOverrides{},
+		aliases:  newAliasTable(),
+	}
+	for _, opt := range opts {
+		opt(configManager)
+	}
+	basePath := configManager.basePath
+	if basePath == "" {
+		basePath = "./configs"
+	}
 	if err := configManager.LoadConfigs(basePath); err != nil {
-		fmt.Printf("Error loading configuration files: %v\n", err)
+		configManager.logf("Error loading configuration files: %v\n", err)
 	}
 
 	return configManager
 }
 
+// logf reports the progress of loading configuration files, to stdout unless WithLogOutput is used
+func (cm *Config) logf(format string, args ...interface{}) {
+	if cm.logOutput == nil {
+		fmt.Printf(format, args...)
+		return
+	}
+	fmt.Fprintf(cm.logOutput, format, args...)
+}
+
+// RegisterLoader registers a loader factory for the given file extensions on this Config only, taking
+// precedence over the global registry
+func (cm *Config) RegisterLoader(extensions []string, factory func() ConfigManager, opts ...LoaderOption) {
+	if cm.loaders == nil {
+		cm.loaders = newLoaderRegistry(defaultLoaders)
+	}
+	cm.loaders.register(extensions, factory, opts...)
+}
+
+// LoaderForMIME returns a loader for the given MIME type, checking loaders registered on this Config
+// before the global registry
+func (cm *Config) LoaderForMIME(mimeType string) (ConfigManager, error) {
+	return cm.registry().forMIME(mimeType)
+}
+
+// registry returns the loader registry of this Config, or the global registry if none was set up
+func (cm *Config) registry() *loaderRegistry {
+	if cm.loaders == nil {
+		return defaultLoaders
+	}
+	return cm.loaders
+}
+
 // LoadConfigs loads configuration files with the following rules:
-// 1. Checks for `.env`, `.json`, `.yaml` in the given order; stops if one is found and loaded.
-// 2. If APP_ENV is set, checks for `APP_ENV.env`, `APP_ENV.json`, `APP_ENV.yaml` in the given order; stops if one is found and loaded.
+// 1. Checks for `.env`, `.json`, `.yaml`, `.yml` (followed by the other registered extensions, by loader priority) in the given order; stops if one is found and loaded.
+// 2. For every profile in APP_ENV (comma-separated, `local` if unset), checks for `<profile>.env`, `<profile>.json`, `<profile>.yaml`, `<profile>.yml`, ... in the given order; stops if one is found and loaded.
+// Profiles are applied in the order listed, and a profile file can inherit from others with an `extends` key.
+// 3. With WithConfD, loads every supported file in `<basePath>/conf.d` in lexical order.
+// Embedded defaults registered with WithEmbeddedDefaults are loaded the same way, from the root of the
+// embedded file system, underneath the files found in basePath.
 func (cm *Config) LoadConfigs(basePath string) error {
 	if basePath == "" {
 		return errors.New("basePath cannot be empty")
 	}
 
-	// Load base files in priority order
-	cm.loadFirstAvailableFile(basePath, []string{".env", ".json", ".yaml"})
+	extensions := cm.registry().discoveryOrder()
+	profiles := newProfileSet(activeProfiles())
 
-	// Load environment-specific files in priority order, if APP_ENV is set
-	appEnv := os.Getenv("APP_ENV")
-	if appEnv == "" {
-		appEnv = "local"
+	cm.origins.reset()
+	cm.nodes.reset()
+	if cm.defaults != nil {
+		if err := cm.loadLayer(source{fsys: cm.defaults, layer: LayerEmbedded}, ".", extensions, profiles); err != nil {
+			return err
+		}
+	}
+	if err := cm.loadLayer(cm.files(), basePath, extensions, profiles); err != nil {
+		return err
 	}
+	cm.applyFlags()
+
+	if err := profiles.checkExtended(); err != nil {
+		return err
+	}
+	return cm.checkAliases()
+}
 
-	if appEnv != "" {
-		cm.loadFirstAvailableFile(basePath, []string{
-			fmt.Sprintf("%s.env", appEnv),
-			fmt.Sprintf("%s.json", appEnv),
-			fmt.Sprintf("%s.yaml", appEnv),
-		})
+// loadLayer loads the base file and the files of the active profiles from a source
+func (cm *Config) loadLayer(src source, basePath string, extensions []string, profiles *profileSet) error {
+	// Load base files in priority order
+	cm.loadFirstAvailableFile(src, basePath, extensions)
+
+	// Load profile files in priority order, in the order the profiles are listed
+	layer := profiles.layer(cm, src, basePath, extensions)
+	for _, profile := range profiles.active {
+		if err := layer.load(profile, nil); err != nil {
+			return err
+		}
 	}
 
+	// Load drop-in files on top
+	if cm.confD {
+		cm.loadDropIns(src, basePath)
+	}
 	return nil
 }
 
+// loadDropIns loads every file with a registered loader from the conf.d directory in basePath, in lexical
+// order. Subdirectories and other files are skipped, and a missing directory is not an error
+func (cm *Config) loadDropIns(src source, basePath string) {
+	dir := src.join(basePath, dropInDir)
+	entries, err := src.readDir(dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			cm.logf("Error reading directory %s: %v\n", dir, err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, supported := cm.registry().forFile(entry.Name()); !supported {
+			continue
+		}
+		fullPath := src.join(dir, entry.Name())
+		if err := cm.loadSourceFile(src, fullPath); err != nil {
+			cm.logf("Error loading file %s: %v\n", fullPath, err)
+		} else {
+			cm.logf("Loaded configuration from %s\n", fullPath)
+		}
+	}
+}
+
 // loadFirstAvailableFile checks and loads the first available file from the list
-func (cm *Config) loadFirstAvailableFile(basePath string, files []string) bool {
+func (cm *Config) loadFirstAvailableFile(src source, basePath string, files []string) bool {
+	fullPath, found := cm.findFile(src, basePath, files)
+	if !found {
+		return false // No file found
+	}
+
+	err := cm.loadSourceFile(src, fullPath)
+	if err != nil {
+		cm.logf("Error loading file %s: %v\n", fullPath, err)
+	} else {
+		cm.logf("Loaded configuration from %s\n", fullPath)
+	}
+	return true // Stop after the first successfully loaded file
+}
+
+// findFile returns the path of the first file from the list that exists in basePath
+func (cm *Config) findFile(src source, basePath string, files []string) (string, bool) {
 	for _, file := range files {
-		fullPath := filepath.Join(basePath, file)
-		if _, err := os.Stat(fullPath); err == nil {
-			err := cm.loadFile(fullPath)
-			if err != nil {
-				fmt.Printf("Error loading file %s: %v\n", fullPath, err)
-			} else {
-				fmt.Printf("Loaded configuration from %s\n", fullPath)
-			}
-			return true // Stop after the first successfully loaded file
+		fullPath := src.join(basePath, file)
+		if _, err := src.stat(fullPath); err == nil {
+			return fullPath, true
 		}
 	}
-	return false // No file found
+	return "", false
+}
+
+// files returns the source that configuration files are read from
+func (cm *Config) files() source {
+	return source{fsys: cm.fsys, layer: LayerFile}
 }
 
 // loadFile uses the appropriate loader to load a configuration file
 func (cm *Config) loadFile(file string) error {
-	loader, err := LoaderFactory(file)
+	return cm.loadSourceFile(cm.files(), file)
+}
+
+// loadSourceFile uses the appropriate loader to load a configuration file from a source
+func (cm *Config) loadSourceFile(src source, file string) error {
+	configs, nulls, err := cm.readSourceFile(src, file)
 	if err != nil {
-		return fmt.Errorf("unsupported file type for %s: %v", file, err)
+		return err
 	}
 
-	configs, err := loader.Load(file)
+	cm.apply(configs, nulls, Origin{Layer: src.layer, Path: file})
+	return nil
+}
+
+// readSourceFile uses the appropriate loader to parse a configuration file from a source without storing
+// its values. Besides the values it returns the keys the file sets to an explicit null
+func (cm *Config) readSourceFile(src source, file string) (map[string]string, map[string]bool, error) {
+	loader, err := cm.registry().loaderFor(file, src.readFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unsupported file type for %s: %v", file, err)
+	}
+
+	var names *encryptionNames
+	if normalizingLoader, ok := loader.(KeyNormalizingLoader); ok && cm.normalizer != nil {
+		if _, isTree := loader.(TreeLoader); isTree {
+			normalizingLoader.SetKeyNormalizer(cm.normalizer)
+		} else {
+			names = &encryptionNames{normalizer: cm.normalizer, names: make(map[string]string)}
+			normalizingLoader.SetKeyNormalizer(names)
+		}
+	}
+
+	fsLoader, readsFiles := loader.(FSLoader)
+	if readsFiles {
+		fsLoader.SetFS(src.fsys)
+	}
+
+	schema, byFile := cm.schemaFor(src, file)
+	if schemaLoader, ok := loader.(SchemaLoader); ok {
+		schemaLoader.SetSchema(schema)
+	} else if byFile {
+		return nil, nil, fmt.Errorf("error loading file %s: loader %T cannot validate against a JSON Schema", file, loader)
+	}
+
+	var configs map[string]string
+	var nulls map[string]bool
+	if treeLoader, ok := loader.(TreeLoader); ok {
+		configs, nulls, err = cm.loadTree(src, treeLoader, file)
+	} else if src.fsys == nil || readsFiles {
+		if configs, err = loader.Load(file); err == nil {
+			err = cm.decryptValues(configs, names)
+		}
+	} else if readerLoader, ok := loader.(ReaderLoader); ok {
+		var content []byte
+		if content, err = src.readFile(file); err == nil {
+			configs, err = readerLoader.LoadReader(bytes.NewReader(content))
+		}
+		if err == nil {
+			err = cm.decryptValues(configs, names)
+		}
+	} else {
+		err = fmt.Errorf("loader %T cannot read from an fs.FS", loader)
+	}
 	if err != nil {
-		return fmt.Errorf("error loading file %s: %v", file, err)
+		return nil, nil, fmt.Errorf("error loading file %s: %v", file, err)
 	}
 
+	return configs, nulls, nil
+}
+
+// apply stores every loaded value with the given origin and records the keys set to an explicit null
+func (cm *Config) apply(configs map[string]string, nulls map[string]bool, origin Origin) {
 	for key, value := range configs {
-		os.Setenv(key, value) // Update environment variables
-		cm.cache.Set(key, value)
+		cm.set(key, value, origin)
+	}
+	for key := range nulls {
+		cm.setNull(key, origin)
 	}
+}
 
-	return nil
+// loadTree loads a file with a TreeLoader, decrypts its values, indexes its nodes and returns the flat view
+// derived from the tree along with its null keys
+func (cm *Config) loadTree(src source, loader TreeLoader, file string) (map[string]string, map[string]bool, error) {
+	content, err := src.readFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := loader.LoadTree(bytes.NewReader(content), file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cm.decryptTree(root); err != nil {
+		return nil, nil, err
+	}
+
+	configs, nodes, err := internal.FlattenTree(root, cm.treeNormalizer())
+	if err != nil {
+		return nil, nil, err
+	}
+	cm.nodes.merge(nodes)
+	return configs, internal.NullKeys(nodes), nil
 }
 
-// GetConfig retrieves a configuration value from the cache or environment variables
-func (cm *Config) GetConfig(key string) string {
-	if value, found := cm.cache.Get(key); found {
-		return value
+// set stores a loaded value and exports it to the environment. Embedded defaults never replace a variable
+// that is already set in the process environment
+func (cm *Config) set(key, value string, origin Origin) {
+	origin.Value = value
+	if origin.Layer == LayerEmbedded {
+		if envValue, found := cm.origins.fromEnvironment(key); found {
+			cm.origins.record(key, origin)
+			cm.origins.record(key, Origin{Layer: LayerEnv, Value: envValue})
+			return
+		}
+	}
+
+	oldValue, existed := cm.cache.Get(key)
+	os.Setenv(key, value) // Update environment variables
+	cm.cache.Set(key, value)
+	cm.origins.markExported(key, value)
+	cm.origins.record(key, origin)
+
+	if !existed || oldValue != value {
+		cm.watchers.notify(Change{Key: key, OldValue: oldValue, NewValue: value, Origin: origin})
 	}
-	return os.Getenv(key)
 }
 
-// GetConfigWithDefault retrieves a configuration value from the cache or environment variables if not found return the default value
-func (cm *Config) GetConfigWithDefault(key,defaultValue string) string {
-	if value, found := cm.cache.Get(key); found {
-		return value
+// setNull records that a layer sets key to an explicit null. A null supplies no value, so the value of a
+// lower layer or a default stays in effect, and nothing is exported to the environment
+func (cm *Config) setNull(key string, origin Origin) {
+	origin.Value = ""
+	origin.Null = true
+	cm.origins.record(key, origin)
+}
+
+// normalizeKey applies the configured KeyNormalizer to a key, leaving it unchanged if there is none
+func (cm *Config) normalizeKey(key string) string {
+	if cm.normalizer == nil {
+		return key
 	}
+	return cm.normalizer.Normalize(key)
+}
 
-	value:=os.Getenv(key)
-	if value==""{
+// lookupEnv looks up the qualified key in the environment, falling back to the key as written so that
+// variables set outside of the configuration files are still found
+func (cm *Config) lookupEnv(key string) (string, bool) {
+	qualified := cm.qualify(key)
+	if value, found := os.LookupEnv(qualified); found || qualified == cm.unqualified(key) {
+		return value, found
+	}
+	return os.LookupEnv(cm.unqualified(key))
+}
+
+// GetConfig retrieves a configuration value from the cache or environment variables, falling back to the
+// deprecated aliases of the key registered with WithAliases
+func (cm *Config) GetConfig(key string) string {
+	return cm.getConfig(cm.qualify(key))
+}
+
+// GetConfigWithDefault retrieves a configuration value from the cache or environment variables if not found return the default value
+func (cm *Config) GetConfigWithDefault(key, defaultValue string) string {
+	value := cm.getConfig(cm.qualify(key))
+	if value == "" {
 		return defaultValue
 	}
 
 	return value
 }
 
-// Unmarshal binds configuration values to a given struct using tags or field names
-func (cm *Config) Unmarshal(target interface{}) error {
+// getConfig looks up a qualified key and its aliases. A conflict between them is logged, and the value of
+// the key itself is returned
+func (cm *Config) getConfig(key string) string {
+	value, found := cm.lookupKey(key)
+	if aliases := cm.tableAliases(key); len(aliases) > 0 {
+		_, resolved, _, err := cm.resolveAliases(key, value, found, aliases)
+		if err != nil {
+			cm.logf("Error: %v\n", err)
+			return value
+		}
+		return resolved
+	}
+	return value
+}
+
+// Unmarshal binds configuration values to a given struct using tags or field names. See WithStrictKeys to
+// also report keys of loaded files that no field consumes
+func (cm *Config) Unmarshal(target interface{}, opts ...UnmarshalOption) error {
 	v := reflect.ValueOf(target)
 	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
 		return errors.New("target must be a pointer to a struct")
 	}
 
-	v = v.Elem()
+	options := &unmarshalOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	consumed := &consumedKeys{}
+	if err := cm.unmarshal(v.Elem(), consumed); err != nil {
+		return err
+	}
+	if options.strict {
+		return cm.checkUnknownKeys(consumed, options.prefixes)
+	}
+	return nil
+}
+
+// unmarshal binds configuration values to the fields of a struct value, recording the keys it consumes
+func (cm *Config) unmarshal(v reflect.Value, consumed *consumedKeys) error {
 	t := v.Type()
 
 	for i := 0; i < v.NumField(); i++ {
@@ -131,21 +428,42 @@ func (cm *Config) Unmarshal(target interface{}) error {
 		fieldType := t.Field(i)
 
 		// Recursive call for nested structs
-		if field.Kind() == reflect.Struct {
-			if err := cm.Unmarshal(field.Addr().Interface()); err != nil {
+		if isNested(field.Type()) {
+			if err := cm.unmarshal(field, consumed); err != nil {
 				return err
 			}
 			continue
 		}
 
-		// Retrieve environment variable key
-		envKey := fieldType.Tag.Get("env")
-		if envKey == "" {
-			envKey = strings.ToUpper(fieldType.Name)
+		// Retrieve environment variable key and value, falling back to deprecated aliases
+		envValue, found := cm.lookupEnv(cm.fieldName(fieldType))
+		envKey := cm.fieldKey(fieldType)
+		structured := field.Kind() == reflect.Slice || field.Kind() == reflect.Map
+		consumed.add(envKey, cm.unqualified(cm.fieldName(fieldType)), structured)
+		var err error
+		if aliases := cm.fieldAliases(envKey, fieldType.Tag.Get("aliases")); len(aliases) > 0 {
+			for _, alias := range aliases {
+				consumed.add(alias, alias, structured)
+			}
+			if envKey, envValue, found, err = cm.resolveAliases(envKey, envValue, found, aliases); err != nil {
+				return fmt.Errorf("error setting field %s: %v", fieldType.Name, err)
+			}
+		}
+
+		// Read secrets mounted as files
+		if envValue, found, err = cm.secretValue(fieldType, envKey, envValue, found, consumed); err != nil {
+			return fmt.Errorf("error setting field %s: %v", fieldType.Name, err)
 		}
 
-		// Retrieve environment variable value
-		envValue, found := os.LookupEnv(envKey)
+		// Bind lists and maps directly from the tree of a structured file
+		if field.Kind() == reflect.Slice || field.Kind() == reflect.Map {
+			if node, ok := cm.structuredNode(envKey, envValue, found); ok {
+				if err := cm.bindNode(field, node, envKey); err != nil {
+					return fmt.Errorf("error setting field %s: %v", fieldType.Name, err)
+				}
+				continue
+			}
+		}
 		if !found {
 			defaultValue := fieldType.Tag.Get("default")
 			if defaultValue != "" {
@@ -166,11 +484,31 @@ func (cm *Config) Unmarshal(target interface{}) error {
 	return nil
 }
 
+// fieldName returns the key of a struct field relative to the view, from its env tag or its name
+func (cm *Config) fieldName(fieldType reflect.StructField) string {
+	if envKey := fieldType.Tag.Get("env"); envKey != "" {
+		return envKey
+	}
+	if cm.normalizer != nil {
+		return fieldType.Name
+	}
+	return strings.ToUpper(fieldType.Name)
+}
+
+// fieldKey returns the full, normalized configuration key of a struct field
+func (cm *Config) fieldKey(fieldType reflect.StructField) string {
+	return cm.qualify(cm.fieldName(fieldType))
+}
+
 // setFieldValue sets a value to a struct field based on its type
 func setFieldValue(field reflect.Value, value string) error {
 	if !field.CanSet() {
 		return errors.New("field cannot be set")
 	}
+	if field.Type() == secretType {
+		field.Set(reflect.ValueOf(NewSecret(value)))
+		return nil
+	}
 
 	switch field.Kind() {
 	case reflect.String:
@@ -193,6 +531,40 @@ func setFieldValue(field reflect.Value, value string) error {
 			return err
 		}
 		field.SetBool(boolValue)
+	case reflect.Slice:
+		// Lists from flat sources are comma-separated, matching the joined form of structured files
+		var parts []string
+		if value != "" {
+			parts = strings.Split(value, ",")
+		}
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setFieldValue(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		// Maps from flat sources are comma-separated key=value pairs
+		if field.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type: %s", field.Type().Key().Kind())
+		}
+		result := reflect.MakeMap(field.Type())
+		for _, pair := range strings.Split(value, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			key, element, found := strings.Cut(pair, "=")
+			if !found {
+				return fmt.Errorf("invalid map entry %q, expected key=value", pair)
+			}
+			elementValue := reflect.New(field.Type().Elem()).Elem()
+			if err := setFieldValue(elementValue, strings.TrimSpace(element)); err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(field.Type().Key()), elementValue)
+		}
+		field.Set(result)
 	default:
 		return fmt.Errorf("unsupported field type: %s", field.Kind())
 	}
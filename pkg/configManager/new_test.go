@@ -1,9 +1,13 @@
 package configManager
 
 import (
+	"io"
 	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/LetsFocus/configManager/pkg/tree"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -104,4 +108,94 @@ func TestLoadConfigs(t *testing.T) {
 
 	err := config.LoadConfigs(basePath)
 	assert.NoError(t, err, "LoadConfigs should not return an error")
-}
\ No newline at end of file
+}
+func TestUnmarshal_Structured(t *testing.T) {
+	type server struct {
+		Name string `env:"name" required:"true"`
+		Port int    `env:"port" default:"80"`
+	}
+	type configStruct struct {
+		Servers []server          `env:"TREE_TEST_SERVERS"`
+		Tags    []string          `env:"TREE_TEST_TAGS"`
+		Limits  map[string]int    `env:"TREE_TEST_LIMITS"`
+		Hosts   []string          `env:"TREE_TEST_HOSTS"`
+		Labels  map[string]string `env:"TREE_TEST_LABELS"`
+	}
+
+	fsys := fstest.MapFS{
+		"configs/.yaml": {Data: []byte("tree_test:\n  servers:\n    - name: primary\n      port: 8080\n    - name: replica\n  tags: [a, b]\n  limits:\n    cpu: 2\n    memory: 512\n")},
+	}
+	os.Setenv("TREE_TEST_HOSTS", "a.example.com, b.example.com")
+	os.Setenv("TREE_TEST_LABELS", "team=core,tier=1")
+	defer func() {
+		for _, key := range os.Environ() {
+			if name, _, _ := strings.Cut(key, "="); strings.HasPrefix(name, "TREE_TEST_") {
+				os.Unsetenv(name)
+			}
+		}
+	}()
+
+	config := New(WithFS(fsys))
+
+	var cfg configStruct
+	err := config.Unmarshal(&cfg)
+	assert.NoError(t, err, "Unmarshal should not return an error")
+	assert.Equal(t, []server{{Name: "primary", Port: 8080}, {Name: "replica", Port: 80}}, cfg.Servers, "lists of structs should be bound from the tree")
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags, "lists of scalars should be bound from the tree")
+	assert.Equal(t, map[string]int{"cpu": 2, "memory": 512}, cfg.Limits, "maps should be bound from the tree")
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, cfg.Hosts, "lists should be split from comma-separated values")
+	assert.Equal(t, map[string]string{"team": "core", "tier": "1"}, cfg.Labels, "maps should be parsed from key=value pairs")
+
+	// Environment variables replace lists loaded from files
+	os.Setenv("TREE_TEST_TAGS", "c")
+	err = config.Unmarshal(&cfg)
+	assert.NoError(t, err, "Unmarshal should not return an error")
+	assert.Equal(t, []string{"c"}, cfg.Tags, "environment variables should override lists from files")
+
+	var missing struct {
+		Servers []server `env:"TREE_TEST_SERVERS"`
+	}
+	config.nodes.merge(map[string]*tree.Node{"TREE_TEST_SERVERS": {Kind: tree.ListNode, Items: []*tree.Node{tree.NewMap(tree.Position{})}}})
+	err = config.Unmarshal(&missing)
+	assert.EqualError(t, err, "error setting field Servers: missing required key: TREE_TEST_SERVERS[0].name")
+}
+
+func TestUnmarshal_NullKeepsDefault(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.yaml":      {Data: []byte("null_test:\n  port:\n  hosts: ~\n  name: api\n")},
+		"configs/local.yaml": {Data: []byte("null_test:\n  name: ~\n")},
+	}
+	defer func() {
+		for _, key := range []string{"NULL_TEST_PORT", "NULL_TEST_HOSTS", "NULL_TEST_NAME"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys), WithLogOutput(io.Discard))
+
+	var cfg struct {
+		Port  int      `env:"NULL_TEST_PORT" default:"8080"`
+		Hosts []string `env:"NULL_TEST_HOSTS" default:"localhost"`
+		Name  string   `env:"NULL_TEST_NAME"`
+	}
+	assert.NoError(t, config.Unmarshal(&cfg), "null keys should read as unset")
+	assert.Equal(t, 8080, cfg.Port, "a null key should still get its default")
+	assert.Equal(t, []string{"localhost"}, cfg.Hosts, "a null list should still get its default")
+	assert.Equal(t, "api", cfg.Name)
+
+	_, found := os.LookupEnv("NULL_TEST_PORT")
+	assert.False(t, found, "null keys should not be exported")
+	assert.Equal(t, "", config.GetConfig("NULL_TEST_PORT"))
+
+	origin, found := config.Origin("NULL_TEST_PORT")
+	assert.True(t, found, "null keys should be told apart from absent keys")
+	assert.Equal(t, Origin{Layer: LayerFile, Path: "configs/.yaml", Null: true}, origin)
+	_, found = config.Origin("NULL_TEST_MISSING")
+	assert.False(t, found)
+
+	origin, _ = config.Origin("NULL_TEST_NAME")
+	assert.Equal(t, "api", origin.Value, "a null should not replace the value of a lower layer")
+	history := config.History("NULL_TEST_NAME")
+	assert.Len(t, history, 2)
+	assert.True(t, history[1].Null, "the null should be kept in the history")
+}
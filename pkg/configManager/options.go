@@ -0,0 +1,81 @@
+package configManager
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+)
+
+// Option customizes a Config created by New
+type Option func(*Config)
+
+// WithFS makes the Config discover and read configuration files from fsys instead of the local disk,
+// e.g. an embed.FS or an fstest.MapFS in tests. Paths given to LoadConfigs are then relative to the
+// root of fsys
+func WithFS(fsys fs.FS) Option {
+	return func(cm *Config) {
+		cm.fsys = fsys
+	}
+}
+
+// WithEmbeddedDefaults registers fsys, typically an embed.FS, as the lowest-precedence configuration layer.
+// Its base and APP_ENV files are discovered at the root of fsys (use fs.Sub to strip a directory) and are
+// overridden by files on disk and by variables already set in the environment
+func WithEmbeddedDefaults(fsys fs.FS) Option {
+	return func(cm *Config) {
+		cm.defaults = fsys
+	}
+}
+
+// WithKeyNormalizer applies normalizer to the keys returned by every loader and to the keys passed to
+// GetConfig, GetConfigWithDefault and Unmarshal, so that all of them agree on one naming scheme
+func WithKeyNormalizer(normalizer KeyNormalizer) Option {
+	return func(cm *Config) {
+		cm.normalizer = normalizer
+	}
+}
+
+// WithConfD loads drop-in files from the conf.d directory inside the base path, e.g. ./configs/conf.d.
+// Every file with a registered extension is loaded in lexical order, so 10-db.yaml is applied before
+// 20-db.env, as a layer above the base and profile files
+func WithConfD() Option {
+	return func(cm *Config) {
+		cm.confD = true
+	}
+}
+
+// WithBasePath makes New load configuration files from basePath instead of ./configs
+func WithBasePath(basePath string) Option {
+	return func(cm *Config) {
+		cm.basePath = basePath
+	}
+}
+
+// WithLogOutput writes the messages about loaded files and load errors to w instead of stdout. Use
+// io.Discard to silence them
+func WithLogOutput(w io.Writer) Option {
+	return func(cm *Config) {
+		cm.logOutput = w
+	}
+}
+
+// WithSchema validates the configuration files matching pattern against schema before they are flattened,
+// so that a file that breaks its schema fails to load with the JSON pointer and line of every error. The
+// pattern uses path.Match syntax and is matched against the base name of a file, or against its whole
+// path if it contains a '/'. Only loaders implementing SchemaLoader, such as JSON and YAML, can validate;
+// other files matching the pattern fail to load
+func WithSchema(pattern string, schema *jsonschema.Schema) Option {
+	return func(cm *Config) {
+		cm.schemas = append(cm.schemas, schemaBinding{pattern: pattern, schema: schema})
+	}
+}
+
+// WithLayerSchema validates every JSON and YAML file of a layer, LayerEmbedded or LayerFile, against
+// schema. Files of other formats in the layer are loaded without validation. A schema attached with
+// WithSchema takes precedence
+func WithLayerSchema(layer string, schema *jsonschema.Schema) Option {
+	return func(cm *Config) {
+		cm.schemas = append(cm.schemas, schemaBinding{layer: layer, schema: schema})
+	}
+}
@@ -0,0 +1,202 @@
+package configManager
+
+import (
+	"io"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.yaml":     {Data: []byte("fs_test:\n  name: base\n  port: 8080\n")},
+		"configs/local.env": {Data: []byte("FS_TEST_NAME=local\n")},
+		"configs/detected":  {Data: []byte("FS_TEST_DETECTED=yes\n")},
+	}
+	defer func() {
+		os.Unsetenv("FS_TEST_NAME")
+		os.Unsetenv("FS_TEST_PORT")
+		os.Unsetenv("FS_TEST_DETECTED")
+	}()
+
+	config := New(WithFS(fsys))
+
+	assert.Equal(t, "local", config.GetConfig("FS_TEST_NAME"), "profile file from the fs.FS should override the base file")
+	assert.Equal(t, "8080", config.GetConfig("FS_TEST_PORT"), "base file should be read from the fs.FS")
+
+	err := config.loadFile("configs/detected")
+	assert.NoError(t, err, "files without an extension should be detected from the fs.FS")
+	assert.Equal(t, "yes", config.GetConfig("FS_TEST_DETECTED"), "detected file should be loaded")
+
+	err = config.loadFile("configs/missing.env")
+	assert.Error(t, err, "missing files should return an error")
+}
+
+func TestWithEmbeddedDefaults(t *testing.T) {
+	defaults := fstest.MapFS{
+		".yaml":     {Data: []byte("embedded_test:\n  name: embedded\n  port: 80\n  host: default-host\n")},
+		"local.env": {Data: []byte("EMBEDDED_TEST_LEVEL=debug\n")},
+	}
+	disk := fstest.MapFS{
+		"configs/.env": {Data: []byte("EMBEDDED_TEST_PORT=8080\n")},
+	}
+	os.Setenv("EMBEDDED_TEST_HOST", "env-host")
+	defer func() {
+		for _, key := range []string{"EMBEDDED_TEST_NAME", "EMBEDDED_TEST_PORT", "EMBEDDED_TEST_HOST", "EMBEDDED_TEST_LEVEL"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(disk), WithEmbeddedDefaults(defaults))
+
+	assert.Equal(t, "embedded", config.GetConfig("EMBEDDED_TEST_NAME"), "embedded defaults should fill missing keys")
+	assert.Equal(t, "debug", config.GetConfig("EMBEDDED_TEST_LEVEL"), "embedded APP_ENV file should be loaded")
+	assert.Equal(t, "8080", config.GetConfig("EMBEDDED_TEST_PORT"), "files on disk should override embedded defaults")
+	assert.Equal(t, "env-host", config.GetConfig("EMBEDDED_TEST_HOST"), "environment variables should override embedded defaults")
+
+	origin, found := config.Origin("EMBEDDED_TEST_NAME")
+	assert.True(t, found, "embedded keys should have an origin")
+	assert.Equal(t, Origin{Layer: LayerEmbedded, Path: ".yaml", Value: "embedded"}, origin, "origin mismatch")
+
+	assert.Equal(t, []Origin{
+		{Layer: LayerEmbedded, Path: ".yaml", Value: "80"},
+		{Layer: LayerFile, Path: "configs/.env", Value: "8080"},
+	}, config.History("EMBEDDED_TEST_PORT"), "history should list every layer")
+
+	origin, _ = config.Origin("EMBEDDED_TEST_HOST")
+	assert.Equal(t, LayerEnv, origin.Layer, "environment should be reported as the effective layer")
+
+	// Reloading must not mistake values exported by the first load for the process environment
+	assert.NoError(t, config.LoadConfigs("configs"), "LoadConfigs should not return an error")
+	origin, _ = config.Origin("EMBEDDED_TEST_NAME")
+	assert.Equal(t, LayerEmbedded, origin.Layer, "reload should keep the embedded origin")
+}
+
+func TestWithKeyNormalizer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.yaml":     {Data: []byte("normalizerTest:\n  maxConns: 10\n  db-host: localhost\n")},
+		"configs/local.env": {Data: []byte("normalizer_test.ReadTimeout=5\n")},
+	}
+	defer func() {
+		for _, key := range []string{"NORMALIZER_TEST_MAX_CONNS", "NORMALIZER_TEST_DB_HOST", "NORMALIZER_TEST_READ_TIMEOUT"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys), WithKeyNormalizer(keys.UpperSnake))
+
+	assert.Equal(t, "10", config.GetConfig("NORMALIZER_TEST_MAX_CONNS"), "structured keys should be split at camelCase boundaries")
+	assert.Equal(t, "10", config.GetConfig("normalizerTest.maxConns"), "GetConfig should normalize the requested key")
+	assert.Equal(t, "localhost", config.GetConfigWithDefault("normalizer-test.db-host", "default"), "GetConfigWithDefault should normalize the requested key")
+	assert.Equal(t, "5", config.GetConfig("NORMALIZER_TEST_READ_TIMEOUT"), ".env keys should be normalized too")
+
+	var cfg struct {
+		NormalizerTestMaxConns int
+		Host                   string `env:"normalizerTest.dbHost"`
+		Timeout                int    `env:"NORMALIZER_TEST_READ_TIMEOUT"`
+	}
+	assert.NoError(t, config.Unmarshal(&cfg), "Unmarshal should not return an error")
+	assert.Equal(t, 10, cfg.NormalizerTestMaxConns, "field names should be normalized")
+	assert.Equal(t, "localhost", cfg.Host, "env tags should be normalized")
+	assert.Equal(t, 5, cfg.Timeout, "normalized env tags should be found")
+}
+
+func TestWithKeyNormalizer_Collision(t *testing.T) {
+	fsys := fstest.MapFS{
+		"collision.yaml": {Data: []byte("db:\n  maxConns: 10\n  max_conns: 20\n")},
+	}
+
+	config := New(WithFS(fsys), WithKeyNormalizer(keys.UpperSnake))
+	err := config.loadFile("collision.yaml")
+	assert.ErrorContains(t, err, `keys "db.maxConns" and "db.max_conns" both normalize to "DB_MAX_CONNS"`, "collisions should be reported")
+}
+
+func TestWithFS_Include(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.yaml":          {Data: []byte("include: shared/*.yaml\ninclude_test:\n  name: app\n")},
+		"configs/shared/db.yaml": {Data: []byte("include_test:\n  name: shared\n  db: postgres\n")},
+	}
+	defer func() {
+		os.Unsetenv("INCLUDE_TEST_NAME")
+		os.Unsetenv("INCLUDE_TEST_DB")
+	}()
+
+	config := New(WithFS(fsys))
+
+	assert.Equal(t, "app", config.GetConfig("INCLUDE_TEST_NAME"), "the including file should override included files")
+	assert.Equal(t, "postgres", config.GetConfig("INCLUDE_TEST_DB"), "included files should be read from the fs.FS")
+}
+
+func TestWithConfD(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.yaml":               {Data: []byte("confd_test:\n  host: localhost\n  port: 80\n  level: info\n")},
+		"configs/conf.d/10-port.json": {Data: []byte(`{"confd_test": {"port": 8080}}`)},
+		"configs/conf.d/20-level.env": {Data: []byte("CONFD_TEST_LEVEL=debug\nCONFD_TEST_PORT=9090\n")},
+		"configs/conf.d/README.md":    {Data: []byte("# drop-ins\n")},
+		"configs/conf.d/old/30-x.env": {Data: []byte("CONFD_TEST_HOST=ignored\n")},
+		"configs/conf.d/99-host.env~": {Data: []byte("CONFD_TEST_HOST=backup\n")},
+	}
+	defer func() {
+		for _, key := range []string{"CONFD_TEST_HOST", "CONFD_TEST_PORT", "CONFD_TEST_LEVEL"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys), WithConfD())
+
+	assert.Equal(t, "localhost", config.GetConfig("CONFD_TEST_HOST"), "unsupported files and subdirectories should be skipped")
+	assert.Equal(t, "9090", config.GetConfig("CONFD_TEST_PORT"), "drop-ins should be applied in lexical order")
+	assert.Equal(t, "debug", config.GetConfig("CONFD_TEST_LEVEL"), "drop-ins should override the base file")
+
+	history := config.History("CONFD_TEST_PORT")
+	if assert.Len(t, history, 3, "every drop-in should be recorded") {
+		assert.Equal(t, "configs/conf.d/10-port.json", history[1].Path)
+		assert.Equal(t, "configs/conf.d/20-level.env", history[2].Path)
+	}
+
+	os.Unsetenv("CONFD_TEST_LEVEL")
+	config = New(WithFS(fsys))
+	assert.Equal(t, "info", config.GetConfig("CONFD_TEST_LEVEL"), "drop-ins should only be loaded with WithConfD")
+}
+
+func TestWithSchema(t *testing.T) {
+	schema := jsonschema.MustCompile([]byte(`{
+		"type": "object",
+		"properties": {
+			"schema_test": {
+				"type": "object",
+				"required": ["host"],
+				"properties": {"port": {"type": "integer", "maximum": 65535}}
+			}
+		}
+	}`))
+	fsys := fstest.MapFS{
+		"configs/.yaml":     {Data: []byte("schema_test:\n  host: localhost\n  port: 8080\n")},
+		"configs/prod.yaml": {Data: []byte("schema_test:\n  port: 70000\n")},
+		"configs/prod.env":  {Data: []byte("SCHEMA_TEST_PORT=1\n")},
+	}
+	defer func() {
+		os.Unsetenv("SCHEMA_TEST_HOST")
+		os.Unsetenv("SCHEMA_TEST_PORT")
+	}()
+
+	config := New(WithFS(fsys), WithLogOutput(io.Discard), WithLayerSchema(LayerFile, schema))
+	assert.NoError(t, config.LoadConfigs("configs"), "a valid file should load")
+	assert.Equal(t, "8080", config.GetConfig("SCHEMA_TEST_PORT"))
+
+	err := config.loadFile("configs/prod.yaml")
+	assert.EqualError(t, err, "error loading file configs/prod.yaml: "+
+		"configs/prod.yaml:2:3: /schema_test: missing required property \"host\"\n"+
+		"configs/prod.yaml:2:9: /schema_test/port: must be at most 65535")
+	assert.NoError(t, config.loadFile("configs/prod.env"), "layer schemas should skip formats that cannot validate")
+
+	config = New(WithFS(fsys), WithLogOutput(io.Discard), WithSchema("prod.*", schema))
+	assert.NoError(t, config.loadFile("configs/.yaml"), "file schemas should only apply to matching files")
+	assert.Error(t, config.loadFile("configs/prod.yaml"))
+	assert.EqualError(t, config.loadFile("configs/prod.env"),
+		"error loading file configs/prod.env: loader *env.EnvLoader cannot validate against a JSON Schema")
+}
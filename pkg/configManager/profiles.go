@@ -0,0 +1,152 @@
+package configManager
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// activeProfiles returns the comma-separated profiles in APP_ENV, in the order they are applied, or
+// local if none are set
+func activeProfiles() []string {
+	profiles := splitProfiles(os.Getenv("APP_ENV"))
+	if len(profiles) == 0 {
+		return []string{"local"}
+	}
+	return profiles
+}
+
+// splitProfiles splits a comma-separated list of profile names, dropping empty entries
+func splitProfiles(value string) []string {
+	var profiles []string
+	for _, profile := range strings.Split(value, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// profileSet tracks the profiles found while loading every layer, so that an extended profile only has
+// to exist in one of them
+type profileSet struct {
+	active   []string
+	found    map[string]bool
+	extended map[string]string // extended profile -> first profile that extends it
+}
+
+func newProfileSet(active []string) *profileSet {
+	return &profileSet{
+		active:   active,
+		found:    make(map[string]bool),
+		extended: make(map[string]string),
+	}
+}
+
+// layer returns a loader for the profile files of one source
+func (p *profileSet) layer(cm *Config, src source, basePath string, extensions []string) *profileLayer {
+	return &profileLayer{
+		profiles:   p,
+		cm:         cm,
+		src:        src,
+		basePath:   basePath,
+		extensions: extensions,
+		applied:    make(map[string]bool),
+	}
+}
+
+// checkExtended reports an extended profile that has no file in any layer
+func (p *profileSet) checkExtended() error {
+	missing := make([]string, 0, len(p.extended))
+	for profile := range p.extended {
+		if !p.found[profile] {
+			missing = append(missing, profile)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("profile %q extends unknown profile %q", p.extended[missing[0]], missing[0])
+}
+
+// profileLayer loads profile files from one source, applying extended profiles before the profiles that
+// extend them
+type profileLayer struct {
+	profiles   *profileSet
+	cm         *Config
+	src        source
+	basePath   string
+	extensions []string
+	applied    map[string]bool
+}
+
+// load applies the file of profile and, first, the files of the profiles it extends. chain holds the
+// profiles currently being loaded, to detect cycles
+func (l *profileLayer) load(profile string, chain []string) error {
+	for _, loading := range chain {
+		if loading == profile {
+			return fmt.Errorf("profile cycle: %s", strings.Join(append(chain, profile), " -> "))
+		}
+	}
+	if l.applied[profile] {
+		return nil
+	}
+
+	files := make([]string, 0, len(l.extensions))
+	for _, ext := range l.extensions {
+		files = append(files, profile+ext)
+	}
+	fullPath, found := l.cm.findFile(l.src, l.basePath, files)
+	if !found {
+		return nil
+	}
+	l.profiles.found[profile] = true
+
+	configs, nulls, err := l.cm.readSourceFile(l.src, fullPath)
+	if err != nil {
+		l.applied[profile] = true
+		l.cm.logf("Error loading file %s: %v\n", fullPath, err)
+		return nil
+	}
+
+	for _, parent := range extractExtends(configs) {
+		if _, seen := l.profiles.extended[parent]; !seen {
+			l.profiles.extended[parent] = profile
+		}
+		if err := l.load(parent, append(chain, profile)); err != nil {
+			return err
+		}
+	}
+	l.applied[profile] = true
+
+	l.cm.apply(configs, nulls, Origin{Layer: l.src.layer, Path: fullPath, Profile: profile})
+	l.cm.logf("Loaded configuration from %s\n", fullPath)
+	return nil
+}
+
+// extractExtends removes the extends key, and the indexed keys of an extends list, from configs and
+// returns the profiles it names
+func extractExtends(configs map[string]string) []string {
+	var parents []string
+	for key, value := range configs {
+		if strings.EqualFold(key, "extends") {
+			parents = splitProfiles(value)
+			delete(configs, key)
+		} else if index, ok := trimPrefix("extends", key); ok && isIndex(index) {
+			delete(configs, key)
+		}
+	}
+	return parents
+}
+
+// isIndex reports whether s is a list index such as the 0 in EXTENDS_0
+func isIndex(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,82 @@
+package configManager
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigs_Profiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.env":          {Data: []byte("PROFILE_TEST_NAME=base\nPROFILE_TEST_REGION=none\nPROFILE_TEST_LEVEL=debug\n")},
+		"configs/base-prod.env": {Data: []byte("PROFILE_TEST_LEVEL=warn\nPROFILE_TEST_REPLICAS=3\n")},
+		"configs/prod.yaml":     {Data: []byte("extends: base-prod\nprofile_test:\n  name: prod\n")},
+		"configs/eu-west.env":   {Data: []byte("PROFILE_TEST_REGION=eu-west\n")},
+		"configs/canary.json":   {Data: []byte(`{"extends": ["prod"], "profile_test": {"replicas": 1}}`)},
+	}
+	os.Setenv("APP_ENV", "prod, eu-west,canary")
+	defer func() {
+		for _, key := range []string{"APP_ENV", "PROFILE_TEST_NAME", "PROFILE_TEST_REGION", "PROFILE_TEST_LEVEL", "PROFILE_TEST_REPLICAS"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys))
+	assert.NoError(t, config.LoadConfigs("configs"), "LoadConfigs should not return an error")
+
+	assert.Equal(t, "prod", config.GetConfig("PROFILE_TEST_NAME"), "active profiles should override the base file")
+	assert.Equal(t, "eu-west", config.GetConfig("PROFILE_TEST_REGION"), "profiles should be applied in order")
+	assert.Equal(t, "warn", config.GetConfig("PROFILE_TEST_LEVEL"), "extended profiles should be loaded")
+	assert.Equal(t, "1", config.GetConfig("PROFILE_TEST_REPLICAS"), "later profiles should override extended profiles")
+	assert.Equal(t, "", config.GetConfig("EXTENDS"), "the extends key should not be exported")
+
+	origin, _ := config.Origin("PROFILE_TEST_LEVEL")
+	assert.Equal(t, Origin{Layer: LayerFile, Path: "configs/base-prod.env", Profile: "base-prod", Value: "warn"}, origin)
+	assert.Equal(t, "file (configs/base-prod.env, profile base-prod)", origin.String())
+
+	history := config.History("PROFILE_TEST_REPLICAS")
+	if assert.Len(t, history, 2, "extended profiles should only be applied once") {
+		assert.Equal(t, "base-prod", history[0].Profile)
+		assert.Equal(t, "canary", history[1].Profile)
+	}
+}
+
+func TestLoadConfigs_ProfileErrors(t *testing.T) {
+	defer os.Unsetenv("APP_ENV")
+
+	tests := []struct {
+		name   string
+		appEnv string
+		files  fstest.MapFS
+		err    string
+	}{
+		{
+			name:   "cycle",
+			appEnv: "a",
+			files: fstest.MapFS{
+				"configs/a.env": {Data: []byte("extends=b\n")},
+				"configs/b.env": {Data: []byte("extends=c\n")},
+				"configs/c.env": {Data: []byte("extends=a\n")},
+			},
+			err: "profile cycle: a -> b -> c -> a",
+		},
+		{
+			name:   "unknown profile",
+			appEnv: "prod",
+			files: fstest.MapFS{
+				"configs/prod.env": {Data: []byte("extends=base-prdo\n")},
+			},
+			err: `profile "prod" extends unknown profile "base-prdo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("APP_ENV", tt.appEnv)
+			config := New(WithFS(tt.files))
+			assert.EqualError(t, config.LoadConfigs("configs"), tt.err)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package properties
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/keys"
+)
+
+// PropertiesLoader implements ConfigLoader for Java .properties files
+type PropertiesLoader struct {
+	normalizer keys.Normalizer
+}
+
+// SetKeyNormalizer sets the normalizer applied to the dot-separated segments of property names. Segments
+// are uppercased and joined with '_' when none is set
+func (p *PropertiesLoader) SetKeyNormalizer(normalizer keys.Normalizer) {
+	p.normalizer = normalizer
+}
+
+// Load parses .properties files and returns key-value pairs, mapping dotted keys such as db.url to DB_URL
+func (p *PropertiesLoader) Load(filePath string) (map[string]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return p.LoadReader(file)
+}
+
+// LoadReader parses .properties content from a reader and returns key-value pairs
+func (p *PropertiesLoader) LoadReader(r io.Reader) (map[string]string, error) {
+	normalizer := p.normalizer
+	if normalizer == nil {
+		normalizer = keys.Upper
+	}
+
+	configs := internal.NewCollector(normalizer)
+	scanner := bufio.NewScanner(r)
+	var logical strings.Builder
+	continued := false
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if !continued && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		// A line ending in an odd number of backslashes continues on the next line
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		continued = trailing%2 == 1
+		if continued {
+			line = line[:len(line)-1]
+		}
+		logical.WriteString(line)
+		if continued {
+			continue
+		}
+
+		err := addLine(configs, logical.String())
+		logical.Reset()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if continued {
+		if err := addLine(configs, logical.String()); err != nil {
+			return nil, err
+		}
+	}
+	return configs.Result(), scanner.Err()
+}
+
+// addLine parses a logical line and adds it to configs under the dot-separated segments of its key
+func addLine(configs *internal.Collector, line string) error {
+	key, value, err := parseLine(line)
+	if err != nil {
+		return err
+	}
+	return configs.Add(strings.Split(key, "."), value)
+}
+
+// parseLine splits a logical line at the first unescaped '=', ':' or whitespace and unescapes both halves
+func parseLine(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '=' || line[i] == ':' || line[i] == ' ' || line[i] == '\t' || line[i] == '\f' {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescape(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescape(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// unescape resolves backslash escapes, including \uXXXX unicode escapes
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, err := parseUnicode(s, i+1)
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// Characters outside the BMP are written as a UTF-16 surrogate pair of escapes
+			if utf16.IsSurrogate(r) && strings.HasPrefix(s[i+1:], `\u`) {
+				if low, err := parseUnicode(s, i+3); err == nil {
+					if combined := utf16.DecodeRune(r, low); combined != utf8.RuneError {
+						r = combined
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parseUnicode decodes the four hex digits of a \uXXXX escape starting at index start
+func parseUnicode(s string, start int) (rune, error) {
+	if start+4 > len(s) {
+		return 0, fmt.Errorf("malformed \\uXXXX escape in %q", s)
+	}
+	code, err := strconv.ParseUint(s[start:start+4], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\uXXXX escape in %q", s)
+	}
+	return rune(code), nil
+}
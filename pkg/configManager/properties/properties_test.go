@@ -0,0 +1,117 @@
+package properties
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropertiesLoader_Load(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileContent string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "Valid properties with all separators",
+			fileContent: `
+db.url=postgres://localhost:5432
+db.user : admin
+server.port 8080
+`,
+			expected: map[string]string{
+				"DB_URL":      "postgres://localhost:5432",
+				"DB_USER":     "admin",
+				"SERVER_PORT": "8080",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid properties with comments",
+			fileContent: `
+# hash comment
+! bang comment
+key=value
+`,
+			expected: map[string]string{
+				"KEY": "value",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid properties with line continuations",
+			fileContent: `
+hosts = alpha,\
+        beta,\
+        gamma
+path = C:\\temp\\
+next = value
+`,
+			expected: map[string]string{
+				"HOSTS": "alpha,beta,gamma",
+				"PATH":  `C:\temp\`,
+				"NEXT":  "value",
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid properties with escapes",
+			fileContent: `
+greeting = caf\u00e9
+emoji = \ud83d\ude00
+escaped\=key = tab\there
+`,
+			expected: map[string]string{
+				"GREETING":    "café",
+				"EMOJI":       "😀",
+				"ESCAPED=KEY": "tab\there",
+			},
+			expectError: false,
+		},
+		{
+			name:        "Malformed unicode escape",
+			fileContent: `key = \u12`,
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name:        "Empty properties content",
+			fileContent: ``,
+			expected:    map[string]string{},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.CreateTemp("", "test_*.properties")
+			assert.NoError(t, err, "Failed to create temp file")
+			defer os.Remove(file.Name())
+
+			_, err = file.WriteString(tt.fileContent)
+			assert.NoError(t, err, "Failed to write to temp file")
+
+			file.Close()
+
+			loader := &PropertiesLoader{}
+			result, err := loader.Load(file.Name())
+
+			if tt.expectError {
+				assert.Error(t, err, "Expected an error but got none")
+			} else {
+				assert.NoError(t, err, "Did not expect an error but got one")
+				assert.Equal(t, tt.expected, result, "Loaded configuration did not match expected")
+			}
+		})
+	}
+}
+
+func TestPropertiesLoader_LoadReader(t *testing.T) {
+	loader := &PropertiesLoader{}
+	result, err := loader.LoadReader(strings.NewReader("db.host=localhost\n"))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"DB_HOST": "localhost"}, result, "Loaded configuration did not match expected")
+}
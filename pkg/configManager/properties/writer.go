@@ -0,0 +1,80 @@
+package properties
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// PropertiesWriter writes configuration as a Java .properties file
+type PropertiesWriter struct{}
+
+// Write writes one key = value line per scalar, with the path of nested keys joined with '.'. Lists of
+// scalars are written comma-joined, and the maps in a list are indexed, e.g. servers.0.name. Nulls are
+// left out, as they are from the flat view
+func (p *PropertiesWriter) Write(w io.Writer, root *tree.Node) error {
+	out := bufio.NewWriter(w)
+	writeNode(out, "", root)
+	return out.Flush()
+}
+
+func writeNode(out *bufio.Writer, key string, node *tree.Node) {
+	switch node.Kind {
+	case tree.MapNode:
+		for _, child := range node.Keys {
+			writeNode(out, joinKey(key, child), node.Children[child])
+		}
+	case tree.ListNode:
+		if joined, ok := node.Joined(); ok {
+			fmt.Fprintf(out, "%s = %s\n", escape(key, true), escape(joined, false))
+			return
+		}
+		for index, item := range node.Items {
+			writeNode(out, joinKey(key, strconv.Itoa(index)), item)
+		}
+	case tree.NullNode:
+	default:
+		fmt.Fprintf(out, "%s = %s\n", escape(key, true), escape(node.String(), false))
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// escape escapes backslashes and control characters, as well as the separators and comment characters of
+// keys and the leading whitespace of values
+func escape(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString(`\ `)
+		case isKey && (r == '=' || r == ':' || r == '#' || r == '!'):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package properties
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropertiesWriter_Escaping(t *testing.T) {
+	root, err := tree.FromValue(map[string]interface{}{
+		"a=b":  "c",
+		"cert": "line one\nline two",
+		"list": []interface{}{"a", map[string]interface{}{"x": 1}, []interface{}{1, 2}},
+	})
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	var out bytes.Buffer
+	err = (&PropertiesWriter{}).Write(&out, root)
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	result, err := (&PropertiesLoader{}).LoadReader(&out)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{
+		"A=B":      "c",
+		"CERT":     "line one\nline two",
+		"LIST_0":   "a",
+		"LIST_1_X": "1",
+		"LIST_2":   "1,2",
+	}, result, "separators, newlines and mixed lists should survive the round trip")
+}
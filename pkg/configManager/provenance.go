@@ -0,0 +1,138 @@
+package configManager
+
+import (
+	"os"
+	"sync"
+)
+
+// Layers that configuration values can originate from, lowest precedence first. LayerSecretFile marks
+// values that Unmarshal reads from secret files, which only apply to keys that are not set otherwise
+const (
+	LayerEmbedded   = "embedded"
+	LayerFile       = "file"
+	LayerEnv        = "env"
+	LayerFlag       = "flag"
+	LayerSecretFile = "secret file"
+)
+
+// Origin describes where a configuration value was loaded from. Profile is set for values from the file
+// of an active or extended profile. Null is set when the file sets the key to an explicit null, which
+// supplies no value: a lower layer or a default stays in effect
+type Origin struct {
+	Layer   string
+	Path    string
+	Profile string
+	Value   string
+	Null    bool
+}
+
+// String formats the origin as "layer", "layer (path)" or "layer (path, profile name)"
+func (o Origin) String() string {
+	switch {
+	case o.Path == "":
+		return o.Layer
+	case o.Profile == "":
+		return o.Layer + " (" + o.Path + ")"
+	default:
+		return o.Layer + " (" + o.Path + ", profile " + o.Profile + ")"
+	}
+}
+
+// provenance records, for every loaded key, each layer that supplied a value in precedence order
+type provenance struct {
+	mu       sync.RWMutex
+	history  map[string][]Origin
+	exported map[string]string
+}
+
+func newProvenance() *provenance {
+	return &provenance{
+		history:  make(map[string][]Origin),
+		exported: make(map[string]string),
+	}
+}
+
+// reset forgets the recorded history before a reload. Exported values are kept so that variables set by
+// an earlier load are not mistaken for the process environment
+func (p *provenance) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.history = make(map[string][]Origin)
+}
+
+func (p *provenance) record(key string, origin Origin) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.history[key] = append(p.history[key], origin)
+}
+
+// recordLatest records origin unless it already is the latest origin of key, for values that are read
+// again on every Unmarshal
+func (p *provenance) recordLatest(key string, origin Origin) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if history := p.history[key]; len(history) > 0 && history[len(history)-1] == origin {
+		return
+	}
+	p.history[key] = append(p.history[key], origin)
+}
+
+func (p *provenance) markExported(key, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.exported[key] = value
+}
+
+// fromEnvironment reports whether the environment holds a value for key that was not exported by the Config
+func (p *provenance) fromEnvironment(key string) (string, bool) {
+	value, found := os.LookupEnv(key)
+	if !found {
+		return "", false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	exported, wasExported := p.exported[key]
+	return value, !wasExported || exported != value
+}
+
+// Origin returns the origin of the value that is currently in effect for key, or the last explicit null
+// if every layer sets key to null
+func (cm *Config) Origin(key string) (Origin, bool) {
+	history := cm.History(key)
+	if len(history) == 0 {
+		return Origin{}, false
+	}
+	return effective(history), true
+}
+
+// History returns every origin that supplied a value for key, from the lowest precedence layer to the
+// highest, including the layers that set it to an explicit null
+func (cm *Config) History(key string) []Origin {
+	cm.origins.mu.RLock()
+	defer cm.origins.mu.RUnlock()
+	return append([]Origin(nil), cm.origins.history[cm.qualify(key)]...)
+}
+
+// Provenance returns the origin in effect for every loaded key, as Origin does, relative to the prefix of
+// a Sub view
+func (cm *Config) Provenance() map[string]Origin {
+	cm.origins.mu.RLock()
+	defer cm.origins.mu.RUnlock()
+	result := make(map[string]Origin, len(cm.origins.history))
+	for key, history := range cm.origins.history {
+		if relative, ok := trimPrefix(cm.prefix, key); ok {
+			result[relative] = effective(history)
+		}
+	}
+	return result
+}
+
+// effective returns the origin in effect in a non-empty history: the last one that is not a null
+func effective(history []Origin) Origin {
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].Null {
+			return history[i]
+		}
+	}
+	return history[len(history)-1]
+}
@@ -0,0 +1,35 @@
+package configManager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrigin_String(t *testing.T) {
+	assert.Equal(t, "env", Origin{Layer: LayerEnv}.String(), "origin without path")
+	assert.Equal(t, "file (configs/.env)", Origin{Layer: LayerFile, Path: "configs/.env"}.String(), "origin with path")
+}
+
+func TestProvenance(t *testing.T) {
+	basePath := t.TempDir()
+	os.WriteFile(filepath.Join(basePath, ".env"), []byte("PROVENANCE_A=base\nPROVENANCE_B=base\n"), 0644)
+	os.WriteFile(filepath.Join(basePath, "local.env"), []byte("PROVENANCE_B=local\n"), 0644)
+	defer func() {
+		os.Unsetenv("PROVENANCE_A")
+		os.Unsetenv("PROVENANCE_B")
+	}()
+
+	config := New()
+	assert.NoError(t, config.LoadConfigs(basePath), "LoadConfigs should not return an error")
+
+	provenance := config.Provenance()
+	assert.Equal(t, Origin{Layer: LayerFile, Path: filepath.Join(basePath, ".env"), Value: "base"}, provenance["PROVENANCE_A"], "origin of PROVENANCE_A")
+	assert.Equal(t, Origin{Layer: LayerFile, Path: filepath.Join(basePath, "local.env"), Profile: "local", Value: "local"}, provenance["PROVENANCE_B"], "origin of PROVENANCE_B")
+	assert.Len(t, config.History("PROVENANCE_B"), 2, "PROVENANCE_B should be supplied by two files")
+
+	_, found := config.Origin("PROVENANCE_MISSING")
+	assert.False(t, found, "missing keys should have no origin")
+}
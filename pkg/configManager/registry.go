@@ -0,0 +1,176 @@
+package configManager
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LoaderOption configures how a loader is registered
+type LoaderOption func(*loaderEntry)
+
+// WithPriority sets the discovery priority of a loader. LoadConfigs tries extensions of loaders with a
+// higher priority first
+func WithPriority(priority int) LoaderOption {
+	return func(entry *loaderEntry) {
+		entry.priority = priority
+	}
+}
+
+// WithMIMETypes associates MIME types with a loader so that it can be found with LoaderForMIME
+func WithMIMETypes(mimeTypes ...string) LoaderOption {
+	return func(entry *loaderEntry) {
+		entry.mimeTypes = append(entry.mimeTypes, mimeTypes...)
+	}
+}
+
+// loaderEntry is a registered loader factory together with the extensions and MIME types it handles
+type loaderEntry struct {
+	extensions []string
+	mimeTypes  []string
+	priority   int
+	sequence   int64
+	factory    func() ConfigManager
+}
+
+// loaderRegistry maps file extensions and MIME types to loader factories. A registry with a parent
+// falls back to the parent for anything it does not override
+type loaderRegistry struct {
+	mu          sync.RWMutex
+	parent      *loaderRegistry
+	byExtension map[string]*loaderEntry
+	byMIMEType  map[string]*loaderEntry
+}
+
+var (
+	// defaultLoaders is the global registry used by LoaderFactory and shared by every Config
+	defaultLoaders = newLoaderRegistry(nil)
+
+	// registrations orders loaders of equal priority across all registries
+	registrations atomic.Int64
+)
+
+func newLoaderRegistry(parent *loaderRegistry) *loaderRegistry {
+	return &loaderRegistry{
+		parent:      parent,
+		byExtension: make(map[string]*loaderEntry),
+		byMIMEType:  make(map[string]*loaderEntry),
+	}
+}
+
+// RegisterLoader registers a loader factory for the given file extensions (e.g. ".toml") in the global
+// registry. Registering an extension that is already known replaces the previous loader
+func RegisterLoader(extensions []string, factory func() ConfigManager, opts ...LoaderOption) {
+	defaultLoaders.register(extensions, factory, opts...)
+}
+
+// LoaderForMIME returns a loader from the global registry for the given MIME type, ignoring any parameters
+// such as charset
+func LoaderForMIME(mimeType string) (ConfigManager, error) {
+	return defaultLoaders.forMIME(mimeType)
+}
+
+func (r *loaderRegistry) register(extensions []string, factory func() ConfigManager, opts ...LoaderOption) {
+	entry := &loaderEntry{factory: factory}
+	for _, ext := range extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		entry.extensions = append(entry.extensions, ext)
+	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+
+	entry.sequence = registrations.Add(1)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ext := range entry.extensions {
+		r.byExtension[ext] = entry
+	}
+	for _, mimeType := range entry.mimeTypes {
+		r.byMIMEType[strings.ToLower(mimeType)] = entry
+	}
+}
+
+// forFile returns a loader for the longest registered extension that filePath ends with
+func (r *loaderRegistry) forFile(filePath string) (ConfigManager, bool) {
+	r.mu.RLock()
+	var match *loaderEntry
+	matchLength := 0
+	for ext, entry := range r.byExtension {
+		if strings.HasSuffix(filePath, ext) && len(ext) > matchLength {
+			match, matchLength = entry, len(ext)
+		}
+	}
+	r.mu.RUnlock()
+
+	if match != nil {
+		return match.factory(), true
+	}
+	if r.parent != nil {
+		return r.parent.forFile(filePath)
+	}
+	return nil, false
+}
+
+func (r *loaderRegistry) forMIME(mimeType string) (ConfigManager, error) {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MIME type %q: %v", mimeType, err)
+	}
+
+	for registry := r; registry != nil; registry = registry.parent {
+		registry.mu.RLock()
+		entry, found := registry.byMIMEType[mediaType]
+		registry.mu.RUnlock()
+		if found {
+			return entry.factory(), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported MIME type: %s", mimeType)
+}
+
+// discoveryOrder lists every registered extension, highest priority first. Extensions of loaders with
+// equal priority keep their registration order
+func (r *loaderRegistry) discoveryOrder() []string {
+	active := make(map[string]*loaderEntry)
+	for registry := r; registry != nil; registry = registry.parent {
+		registry.mu.RLock()
+		for ext, entry := range registry.byExtension {
+			if _, shadowed := active[ext]; !shadowed {
+				active[ext] = entry
+			}
+		}
+		registry.mu.RUnlock()
+	}
+
+	entries := make([]*loaderEntry, 0, len(active))
+	seen := make(map[*loaderEntry]bool)
+	for _, entry := range active {
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority > entries[j].priority
+		}
+		return entries[i].sequence < entries[j].sequence
+	})
+
+	var order []string
+	for _, entry := range entries {
+		for _, ext := range entry.extensions {
+			if active[ext] == entry {
+				order = append(order, ext)
+			}
+		}
+	}
+	return order
+}
@@ -0,0 +1,135 @@
+package configManager
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/configManager/json"
+	"github.com/LetsFocus/configManager/pkg/configManager/yaml"
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+// staticLoader returns the same configs for every file
+type staticLoader struct {
+	configs map[string]string
+}
+
+func (s *staticLoader) Load(filePath string) (map[string]string, error) {
+	return s.configs, nil
+}
+
+// staticTreeLoader returns the same tree for every file
+type staticTreeLoader struct {
+	staticLoader
+	root *tree.Node
+}
+
+func (s *staticTreeLoader) LoadTree(r io.Reader, fileName string) (*tree.Node, error) {
+	return s.root, nil
+}
+
+func TestRegisterLoader(t *testing.T) {
+	RegisterLoader([]string{"registrytest"}, func() ConfigManager {
+		return &staticLoader{configs: map[string]string{"KEY": "global"}}
+	})
+
+	loader, err := LoaderFactory("config.registrytest")
+	assert.NoError(t, err, "LoaderFactory should find a registered extension")
+	assert.IsType(t, &staticLoader{}, loader, "loader type mismatch")
+}
+
+func TestConfig_RegisterLoader(t *testing.T) {
+	config := New()
+	config.RegisterLoader([]string{".yaml"}, func() ConfigManager {
+		return &staticLoader{configs: map[string]string{"REGISTRY_OVERRIDE": "per-config"}}
+	})
+
+	loader, err := config.registry().loaderFor("config.yaml", os.ReadFile)
+	assert.NoError(t, err, "per-config registry should resolve overridden extensions")
+	assert.IsType(t, &staticLoader{}, loader, "per-config loader should take precedence")
+
+	loader, err = config.registry().loaderFor("config.json", os.ReadFile)
+	assert.NoError(t, err, "per-config registry should fall back to the global registry")
+	assert.IsType(t, &json.JSONLoader{}, loader, "loader type mismatch")
+
+	loader, err = LoaderFactory("config.yaml")
+	assert.NoError(t, err, "global registry should be unaffected")
+	assert.IsType(t, &yaml.YAMLLoader{}, loader, "global registry should be unaffected by per-config overrides")
+
+	basePath := t.TempDir()
+	os.WriteFile(filepath.Join(basePath, "local.yaml"), []byte("ignored: true"), 0644)
+	defer os.Unsetenv("REGISTRY_OVERRIDE")
+
+	err = config.LoadConfigs(basePath)
+	assert.NoError(t, err, "LoadConfigs should not return an error")
+	assert.Equal(t, "per-config", config.GetConfig("REGISTRY_OVERRIDE"), "LoadConfigs should use the per-config loader")
+}
+
+func TestLoaderRegistry_DiscoveryOrder(t *testing.T) {
+	parent := newLoaderRegistry(nil)
+	parent.register([]string{".low"}, nil, WithPriority(1))
+	parent.register([]string{".high", ".higher"}, nil, WithPriority(10))
+	parent.register([]string{".mid"}, nil, WithPriority(5))
+
+	child := newLoaderRegistry(parent)
+	child.register([]string{".low"}, nil, WithPriority(20))
+	child.register([]string{".tie"}, nil, WithPriority(5))
+
+	assert.Equal(t, []string{".high", ".higher", ".mid", ".low"}, parent.discoveryOrder(), "parent order mismatch")
+	assert.Equal(t, []string{".low", ".high", ".higher", ".mid", ".tie"}, child.discoveryOrder(), "child order mismatch")
+}
+
+func TestLoaderForMIME(t *testing.T) {
+	tests := []struct {
+		name         string
+		mimeType     string
+		expectedType interface{}
+		expectError  bool
+	}{
+		{name: "JSON", mimeType: "application/json", expectedType: &json.JSONLoader{}},
+		{name: "JSON with parameters", mimeType: "application/json; charset=utf-8", expectedType: &json.JSONLoader{}},
+		{name: "YAML", mimeType: "application/x-yaml", expectedType: &yaml.YAMLLoader{}},
+		{name: "Unknown type", mimeType: "application/octet-stream", expectError: true},
+		{name: "Malformed type", mimeType: "not a mime type;", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader, err := LoaderForMIME(tt.mimeType)
+			if tt.expectError {
+				assert.Error(t, err, "expected an error")
+				assert.Nil(t, loader, "expected loader to be nil")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+				assert.IsType(t, tt.expectedType, loader, "loader type mismatch")
+			}
+		})
+	}
+}
+
+func TestConfig_RegisterLoader_Tree(t *testing.T) {
+	root, _ := tree.FromValue(map[string]interface{}{
+		"registry_tree": map[string]interface{}{"servers": []interface{}{map[string]interface{}{"port": 8080}}},
+	})
+	config := New(WithLogOutput(io.Discard))
+	config.RegisterLoader([]string{".yaml"}, func() ConfigManager {
+		return &staticTreeLoader{root: root}
+	})
+
+	basePath := t.TempDir()
+	os.WriteFile(filepath.Join(basePath, "local.yaml"), []byte("ignored: true"), 0644)
+	defer os.Unsetenv("REGISTRY_TREE_SERVERS_0_PORT")
+	assert.NoError(t, config.LoadConfigs(basePath))
+
+	var cfg struct {
+		Servers []struct {
+			Port int `env:"port"`
+		} `env:"REGISTRY_TREE_SERVERS"`
+	}
+	assert.NoError(t, config.Unmarshal(&cfg))
+	assert.Len(t, cfg.Servers, 1, "registered loaders should be able to provide a tree")
+	assert.Equal(t, 8080, cfg.Servers[0].Port)
+}
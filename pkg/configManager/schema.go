@@ -0,0 +1,256 @@
+package configManager
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+)
+
+// JSONSchemaDraft is the $schema URI of the JSON Schemas generated by GenerateJSONSchema
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEntry is a field of the target struct, with its key split into path segments
+type schemaEntry struct {
+	path []string
+	spec fieldSpec
+}
+
+// GenerateJSONSchema describes the YAML and JSON configuration files that Unmarshal can read into target, a
+// struct or a pointer to one, as a draft 2020-12 JSON Schema. Keys come from the env tag or the name of each
+// field like in Unmarshal, lowercased and nested at '_' where several keys share a prefix, so DB_HOST and
+// DB_PORT become db.host and db.port. The default, required, allowed and desc tags become default,
+// required, enum and description
+func GenerateJSONSchema(target interface{}) ([]byte, error) {
+	t := reflect.TypeOf(target)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("target must be a struct or a pointer to a struct")
+	}
+
+	var entries []schemaEntry
+	for _, spec := range (&Config{}).fieldSpecs(t) {
+		entries = append(entries, schemaEntry{path: strings.Split(strings.ToLower(spec.Key), "_"), spec: spec})
+	}
+
+	schema := objectSchema(entries)
+	schema["$schema"] = JSONSchemaDraft
+	if t.Name() != "" {
+		schema["title"] = t.Name()
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// objectSchema builds the schema of an object holding entries, nesting the entries that share their first
+// path segment with another entry
+func objectSchema(entries []schemaEntry) map[string]interface{} {
+	groups := make(map[string][]schemaEntry)
+	var names []string
+	for _, entry := range entries {
+		if _, exists := groups[entry.path[0]]; !exists {
+			names = append(names, entry.path[0])
+		}
+		groups[entry.path[0]] = append(groups[entry.path[0]], entry)
+	}
+
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, name := range names {
+		group := groups[name]
+		if !nestable(group) {
+			for _, entry := range group {
+				key := strings.Join(entry.path, "_")
+				properties[key] = fieldSchema(entry.spec)
+				if entry.spec.Required {
+					required = append(required, key)
+				}
+			}
+			continue
+		}
+
+		children := make([]schemaEntry, 0, len(group))
+		groupRequired := false
+		for _, entry := range group {
+			children = append(children, schemaEntry{path: entry.path[1:], spec: entry.spec})
+			groupRequired = groupRequired || entry.spec.Required
+		}
+		properties[name] = objectSchema(children)
+		if groupRequired {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// nestable reports whether the entries sharing a first path segment can be nested below it: there must
+// be more than one, and none of them may end at the shared segment
+func nestable(group []schemaEntry) bool {
+	if len(group) < 2 {
+		return false
+	}
+	for _, entry := range group {
+		if len(entry.path) < 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldSchema builds the schema of a single field from its type and tags
+func fieldSchema(spec fieldSpec) map[string]interface{} {
+	schema := typeSchema(spec.goType)
+	if spec.Description != "" {
+		schema["description"] = spec.Description
+	}
+	if spec.Default != "" {
+		schema["default"] = typedValue(spec.goType, spec.Default)
+	}
+	if spec.Allowed != nil {
+		values := make([]interface{}, 0, len(spec.Allowed))
+		for _, value := range spec.Allowed {
+			values = append(values, typedValue(spec.goType, value))
+		}
+		schema["enum"] = values
+	}
+	return schema
+}
+
+// typeSchema describes the values that setFieldValue and the tree binding accept for a Go type
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == secretType {
+		return map[string]interface{}{"type": "string", "writeOnly": true}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			fieldType := t.Field(i)
+			if !fieldType.IsExported() {
+				continue
+			}
+			key := fieldType.Tag.Get("env")
+			if key == "" {
+				key = fieldType.Name
+			}
+			key = strings.ToLower(key)
+			properties[key] = fieldSchema(fieldSpec{
+				Default:     fieldType.Tag.Get("default"),
+				Description: fieldType.Tag.Get("desc"),
+				Allowed:     allowedValues(fieldType),
+				goType:      fieldType.Type,
+			})
+			if fieldType.Tag.Get("required") == "true" {
+				required = append(required, key)
+			}
+		}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// typedValue converts a tag value into the JSON type of the field, keeping it as a string if it does not
+// parse
+func typedValue(t reflect.Type, value string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return integer
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if integer, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return integer
+		}
+	case reflect.Float32, reflect.Float64:
+		if float, err := strconv.ParseFloat(value, 64); err == nil {
+			return float
+		}
+	case reflect.Bool:
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	case reflect.Slice:
+		items := []interface{}{}
+		if value != "" {
+			for _, item := range strings.Split(value, ",") {
+				items = append(items, typedValue(t.Elem(), strings.TrimSpace(item)))
+			}
+		}
+		return items
+	}
+	return value
+}
+
+// schemaBinding attaches a JSON Schema to the files matching a pattern or to the files of a layer
+type schemaBinding struct {
+	pattern string
+	layer   string
+	schema  *jsonschema.Schema
+}
+
+// schemaFor returns the schema attached to a file of src, and whether it was attached to the file itself
+// rather than to its layer. The first file binding that matches wins, then the first layer binding
+func (cm *Config) schemaFor(src source, file string) (*jsonschema.Schema, bool) {
+	name := filepath.ToSlash(file)
+	for _, binding := range cm.schemas {
+		if binding.pattern == "" {
+			continue
+		}
+		subject := path.Base(name)
+		if strings.Contains(binding.pattern, "/") {
+			subject = path.Clean(name)
+		}
+		if matched, _ := path.Match(binding.pattern, subject); matched {
+			return binding.schema, true
+		}
+	}
+
+	for _, binding := range cm.schemas {
+		if binding.pattern == "" && binding.layer == src.layer {
+			return binding.schema, false
+		}
+	}
+	return nil, false
+}
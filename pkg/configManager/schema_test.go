@@ -0,0 +1,58 @@
+package configManager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaConfig struct {
+	DBHost   string   `env:"DB_HOST" required:"true" desc:"database host"`
+	DBPort   int      `env:"DB_PORT" default:"5432"`
+	LogLevel string   `env:"LOG_LEVEL" default:"info" allowed:"debug,info"`
+	Debug    bool     `default:"false"`
+	Tags     []string `env:"TAGS" default:"a,b"`
+	Replicas []struct {
+		Name string `env:"NAME" required:"true"`
+		Port int    `env:"PORT"`
+	} `env:"REPLICAS"`
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	data, err := GenerateJSONSchema(&schemaConfig{})
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	expected := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "schemaConfig",
+		"type": "object",
+		"required": ["db"],
+		"properties": {
+			"db": {
+				"type": "object",
+				"required": ["host"],
+				"properties": {
+					"host": {"type": "string", "description": "database host"},
+					"port": {"type": "integer", "default": 5432}
+				}
+			},
+			"log_level": {"type": "string", "default": "info", "enum": ["debug", "info"]},
+			"debug": {"type": "boolean", "default": false},
+			"tags": {"type": "array", "items": {"type": "string"}, "default": ["a", "b"]},
+			"replicas": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["name"],
+					"properties": {"name": {"type": "string"}, "port": {"type": "integer"}}
+				}
+			}
+		}
+	}`
+	assert.JSONEq(t, expected, string(data), "schema did not match expected")
+	assert.True(t, json.Valid(data), "schema should be valid JSON")
+
+	_, err = GenerateJSONSchema("not a struct")
+	assert.EqualError(t, err, "target must be a struct or a pointer to a struct")
+}
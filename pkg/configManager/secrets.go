@@ -0,0 +1,140 @@
+package configManager
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// redacted is how a Secret prints
+const redacted = "[REDACTED]"
+
+// Secret holds a sensitive value, such as a password or token, that must not end up in logs. It prints,
+// logs and marshals as [REDACTED]; only Reveal returns the value. Unmarshal, BindFlags and the tree binding
+// set Secret fields like string fields
+type Secret struct {
+	value string
+}
+
+// secretType is the type setFieldValue sets directly rather than as a nested struct
+var secretType = reflect.TypeOf(Secret{})
+
+// NewSecret wraps a value in a Secret
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the value of the secret
+func (s Secret) Reveal() string {
+	return s.value
+}
+
+// String implements fmt.Stringer, so %v, %+v and %s print [REDACTED]
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer, so %#v prints [REDACTED]
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// MarshalJSON writes the secret as the JSON string "[REDACTED]"
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// MarshalText writes the secret as [REDACTED], for encoders such as YAML and slog's text handler
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// LogValue implements slog.LogValuer
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// isNested reports whether a field of type t is a nested struct whose fields are bound one by one
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != secretType
+}
+
+// defaultSecretFileLimit is the largest secret file Unmarshal reads unless WithSecretFileLimit is used
+const defaultSecretFileLimit = 1 << 20
+
+// WithSecretFileLimit changes the largest secret file, in bytes, that Unmarshal reads for the KEY_FILE
+// convention and file:"true" fields. The default is 1 MiB
+func WithSecretFileLimit(limit int64) Option {
+	return func(cm *Config) {
+		cm.secretFileLimit = limit
+	}
+}
+
+// secretValue reads the value of a field from a secret file, as mounted by Docker and Kubernetes. The file
+// is named by the KEY_FILE variable when KEY itself is not set, or by the value of KEY for fields tagged
+// file:"true". Setting both KEY and KEY_FILE is an error. Values read from files are recorded in the
+// provenance of KEY with the path of the file
+func (cm *Config) secretValue(fieldType reflect.StructField, key, value string, found bool, consumed *consumedKeys) (string, bool, error) {
+	fileName := cm.fieldName(fieldType) + "_FILE"
+	fileKey := cm.qualify(fileName)
+	consumed.add(fileKey, cm.unqualified(fileName), false)
+
+	path, pathFound := cm.lookupEnv(fileName)
+	pathFound = pathFound && path != ""
+	switch {
+	case pathFound && found:
+		return "", false, fmt.Errorf("both %s and %s are set", key, fileKey)
+	case found && fieldType.Tag.Get("file") == "true":
+		path = value
+	case !pathFound:
+		return value, found, nil
+	}
+
+	secret, err := cm.readSecretFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	cm.origins.recordLatest(key, Origin{Layer: LayerSecretFile, Path: path})
+	return secret, true, nil
+}
+
+// readSecretFile reads a secret file, trimming one trailing newline. The file must be a regular file (or
+// a symlink to one, as Kubernetes mounts them), must not be writable by other users and must fit in the
+// size limit
+func (cm *Config) readSecretFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read secret file: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("cannot read secret file: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("secret file %s is not a regular file", path)
+	}
+	if info.Mode().Perm()&0o002 != 0 {
+		return "", fmt.Errorf("secret file %s is writable by other users (mode %v)", path, info.Mode().Perm())
+	}
+
+	limit := cm.secretFileLimit
+	if limit <= 0 {
+		limit = defaultSecretFileLimit
+	}
+	// the size is checked on what is read, since the file may grow after Stat
+	content, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return "", fmt.Errorf("cannot read secret file: %v", err)
+	}
+	if int64(len(content)) > limit {
+		return "", fmt.Errorf("secret file %s is larger than %d bytes", path, limit)
+	}
+
+	value := strings.TrimSuffix(string(content), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
@@ -0,0 +1,121 @@
+package configManager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestUnmarshal_SecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	password := filepath.Join(dir, "db_password")
+	os.WriteFile(password, []byte("s3cret\n"), 0400)
+	certificate := filepath.Join(dir, "tls.crt")
+	os.WriteFile(certificate, []byte("line one\nline two\n\n"), 0644)
+
+	fsys := fstest.MapFS{"configs/.env": {Data: []byte("SECRET_TEST_CERT=" + certificate + "\n")}}
+	os.Setenv("SECRET_TEST_PASSWORD_FILE", password)
+	defer func() {
+		for _, key := range []string{"SECRET_TEST_PASSWORD_FILE", "SECRET_TEST_PASSWORD", "SECRET_TEST_CERT", "SECRET_TEST_TOKEN"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	var cfg struct {
+		Password string `env:"SECRET_TEST_PASSWORD" required:"true"`
+		Cert     string `env:"SECRET_TEST_CERT" file:"true"`
+		Token    string `env:"SECRET_TEST_TOKEN"`
+	}
+	config := New(WithFS(fsys), WithLogOutput(io.Discard))
+	assert.NoError(t, config.Unmarshal(&cfg, WithStrictKeys()), "KEY_FILE variables should count as consumed keys")
+	assert.Equal(t, "s3cret", cfg.Password, "KEY_FILE should name the file holding the value, without its trailing newline")
+	assert.Equal(t, "line one\nline two\n", cfg.Cert, "file:\"true\" fields should read the file named by their value")
+
+	history := config.History("SECRET_TEST_CERT")
+	assert.Equal(t, Origin{Layer: LayerSecretFile, Path: certificate}, history[len(history)-1], "provenance should point at the secret file")
+	assert.NoError(t, config.Unmarshal(&cfg))
+	assert.Len(t, config.History("SECRET_TEST_CERT"), len(history), "reading the file again should not grow the history")
+	assert.Equal(t, "secret file ("+password+")", config.History("SECRET_TEST_PASSWORD")[0].String())
+
+	os.Setenv("SECRET_TEST_PASSWORD", "plain")
+	assert.EqualError(t, config.Unmarshal(&cfg), "error setting field Password: both SECRET_TEST_PASSWORD and SECRET_TEST_PASSWORD_FILE are set")
+	os.Unsetenv("SECRET_TEST_PASSWORD")
+
+	os.Setenv("SECRET_TEST_TOKEN_FILE", filepath.Join(dir, "missing"))
+	err := config.Unmarshal(&cfg)
+	assert.ErrorContains(t, err, "error setting field Token: cannot read secret file: open "+filepath.Join(dir, "missing"))
+
+	os.Setenv("SECRET_TEST_TOKEN_FILE", dir)
+	assert.EqualError(t, config.Unmarshal(&cfg), "error setting field Token: secret file "+dir+" is not a regular file")
+
+	writable := filepath.Join(dir, "writable")
+	os.WriteFile(writable, []byte("token"), 0600)
+	os.Chmod(writable, 0666)
+	os.Setenv("SECRET_TEST_TOKEN_FILE", writable)
+	assert.EqualError(t, config.Unmarshal(&cfg), "error setting field Token: secret file "+writable+" is writable by other users (mode -rw-rw-rw-)")
+
+	large := filepath.Join(dir, "large")
+	os.WriteFile(large, []byte(strings.Repeat("x", 25)), 0600)
+	os.Setenv("SECRET_TEST_TOKEN_FILE", large)
+	config = New(WithFS(fsys), WithLogOutput(io.Discard), WithSecretFileLimit(24))
+	assert.EqualError(t, config.Unmarshal(&cfg), "error setting field Token: secret file "+large+" is larger than 24 bytes")
+	os.Unsetenv("SECRET_TEST_TOKEN_FILE")
+}
+
+func TestSecret(t *testing.T) {
+	fsys := fstest.MapFS{"configs/.yaml": {Data: []byte("secret_test:\n  password: s3cret\n  tokens: [one, two]\n  db:\n    password: nested\n")}}
+	defer func() {
+		for _, key := range []string{"SECRET_TEST_PASSWORD", "SECRET_TEST_TOKENS", "SECRET_TEST_TOKENS_0", "SECRET_TEST_TOKENS_1", "SECRET_TEST_DB", "SECRET_TEST_DB_PASSWORD", "SECRET_TEST_API_KEY"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	var cfg struct {
+		Password Secret   `env:"SECRET_TEST_PASSWORD" required:"true"`
+		Tokens   []Secret `env:"SECRET_TEST_TOKENS"`
+		APIKey   Secret   `env:"SECRET_TEST_API_KEY" default:"dev-key"`
+		DB       struct {
+			Password Secret `env:"SECRET_TEST_DB_PASSWORD"`
+		}
+	}
+	config := New(WithFS(fsys), WithLogOutput(io.Discard))
+	assert.NoError(t, config.LoadConfigs("configs"))
+	assert.NoError(t, config.Unmarshal(&cfg, WithStrictKeys("SECRET_TEST")))
+	assert.Equal(t, "s3cret", cfg.Password.Reveal())
+	assert.Equal(t, []string{"one", "two"}, []string{cfg.Tokens[0].Reveal(), cfg.Tokens[1].Reveal()}, "lists of secrets should bind from the tree")
+	assert.Equal(t, "dev-key", cfg.APIKey.Reveal(), "defaults should apply to secrets")
+	assert.Equal(t, "nested", cfg.DB.Password.Reveal())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		assert.NotContains(t, fmt.Sprintf(format, cfg), "s3cret", "%s should not print the value", format)
+	}
+	assert.Equal(t, "[REDACTED]", cfg.Password.String())
+	assert.Equal(t, "[REDACTED]", fmt.Sprintf("%#v", cfg.Password))
+
+	data, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Password":"[REDACTED]","Tokens":["[REDACTED]","[REDACTED]"],"APIKey":"[REDACTED]","DB":{"Password":"[REDACTED]"}}`, string(data))
+	data, err = yaml.Marshal(map[string]Secret{"password": cfg.Password})
+	assert.NoError(t, err)
+	assert.Equal(t, "password: '[REDACTED]'\n", string(data))
+
+	var logs bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logs, nil)).Info("loaded", "password", cfg.Password)
+	slog.New(slog.NewTextHandler(&logs, nil)).Info("loaded", "password", cfg.Password)
+	assert.NotContains(t, logs.String(), "s3cret")
+	assert.Contains(t, logs.String(), `"password":"[REDACTED]"`)
+	assert.Contains(t, logs.String(), `password=[REDACTED]`)
+
+	assert.Equal(t, "s3cret", NewSecret("s3cret").Reveal())
+	assert.Equal(t, "", Secret{}.Reveal())
+}
@@ -0,0 +1,47 @@
+package configManager
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// source is a file system that configuration files are discovered in and read from, together with the
+// provenance layer that its values are recorded under
+type source struct {
+	fsys  fs.FS // nil reads from the local disk
+	layer string
+}
+
+// join joins path elements using the separator of the source's file system
+func (s source) join(elem ...string) string {
+	if s.fsys != nil {
+		return path.Join(elem...)
+	}
+	return filepath.Join(elem...)
+}
+
+// stat describes a file in the source
+func (s source) stat(name string) (fs.FileInfo, error) {
+	if s.fsys != nil {
+		return fs.Stat(s.fsys, name)
+	}
+	return os.Stat(name)
+}
+
+// readFile reads a whole file from the source
+func (s source) readFile(name string) ([]byte, error) {
+	if s.fsys != nil {
+		return fs.ReadFile(s.fsys, name)
+	}
+	return os.ReadFile(name)
+}
+
+// readDir lists a directory of the source, sorted by file name
+func (s source) readDir(name string) ([]fs.DirEntry, error) {
+	if s.fsys != nil {
+		return fs.ReadDir(s.fsys, name)
+	}
+	return os.ReadDir(name)
+}
@@ -0,0 +1,179 @@
+package configManager
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnmarshalOption customizes a single call to Unmarshal
+type UnmarshalOption func(*unmarshalOptions)
+
+type unmarshalOptions struct {
+	strict   bool
+	prefixes []string
+}
+
+// WithStrictKeys makes Unmarshal fail with an *UnknownKeysError when loaded files define keys that no field
+// of the target consumes, such as a DB_ULR typo next to DB_URL. Keys that only come from the environment
+// or from flags are never reported. With prefixes, relative to the view like the keys of a Sub, only the
+// keys below one of them are checked, so several structs can share the files
+func WithStrictKeys(prefixes ...string) UnmarshalOption {
+	return func(options *unmarshalOptions) {
+		options.strict = true
+		options.prefixes = append(options.prefixes, prefixes...)
+	}
+}
+
+// UnknownKey is a key of a loaded file that no struct field consumes
+type UnknownKey struct {
+	Key    string
+	Origin Origin
+	// Suggestion is the closest key of a struct field, or empty if none is close enough
+	Suggestion string
+}
+
+// String formats the key as `unknown key KEY from origin`, with a suggestion if there is one
+func (k UnknownKey) String() string {
+	message := "unknown key " + k.Key + " from " + k.Origin.String()
+	if k.Suggestion != "" {
+		message += ", did you mean " + k.Suggestion + "?"
+	}
+	return message
+}
+
+// UnknownKeysError is returned by Unmarshal in strict mode. Keys are sorted
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+// Error lists the unknown keys one per line
+func (e *UnknownKeysError) Error() string {
+	messages := make([]string, 0, len(e.Keys))
+	for _, key := range e.Keys {
+		messages = append(messages, key.String())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// consumedKeys are the keys read by the fields of an Unmarshal target. Lists and maps also consume the
+// keys below their own, such as HOSTS_0
+type consumedKeys struct {
+	keys     []string
+	prefixes []string
+}
+
+// add records the qualified and unqualified key of a field
+func (c *consumedKeys) add(key, unqualified string, structured bool) {
+	c.keys = append(c.keys, key)
+	if unqualified != key {
+		c.keys = append(c.keys, unqualified)
+	}
+	if structured {
+		c.prefixes = append(c.prefixes, key, unqualified)
+	}
+}
+
+// has reports whether key is consumed, comparing keys case-insensitively like trimPrefix
+func (c *consumedKeys) has(key string) bool {
+	for _, consumed := range c.keys {
+		if strings.EqualFold(consumed, key) {
+			return true
+		}
+	}
+	for _, prefix := range c.prefixes {
+		if _, ok := trimPrefix(prefix, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnknownKeys reports the keys of loaded files within the view and the given prefixes that are not
+// consumed
+func (cm *Config) checkUnknownKeys(consumed *consumedKeys, prefixes []string) error {
+	scopes := []string{cm.prefix}
+	if len(prefixes) > 0 {
+		scopes = scopes[:0]
+		for _, prefix := range prefixes {
+			scopes = append(scopes, cm.qualify(prefix))
+		}
+	}
+
+	var unknown []UnknownKey
+	cm.origins.mu.RLock()
+	for key, history := range cm.origins.history {
+		origin, fromFile := lastFileOrigin(history)
+		if !fromFile || !inScope(key, scopes) || consumed.has(key) {
+			continue
+		}
+		unknown = append(unknown, UnknownKey{Key: key, Origin: origin, Suggestion: suggest(key, consumed.keys)})
+	}
+	cm.origins.mu.RUnlock()
+
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Key < unknown[j].Key })
+	return &UnknownKeysError{Keys: unknown}
+}
+
+// lastFileOrigin returns the last origin of a key that is a configuration file
+func lastFileOrigin(history []Origin) (Origin, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Layer == LayerFile || history[i].Layer == LayerEmbedded {
+			return history[i], true
+		}
+	}
+	return Origin{}, false
+}
+
+// inScope reports whether key is one of the prefixes or below one of them
+func inScope(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if _, ok := trimPrefix(prefix, key); ok || strings.EqualFold(prefix, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the candidate closest to key by edit distance, if it is within a third of the length of
+// key. Ties go to the candidate that sorts first
+func suggest(key string, candidates []string) string {
+	best, bestDistance := "", len(key)/3+1
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToUpper(key), strings.ToUpper(candidate))
+		if distance < bestDistance || (distance == bestDistance && best != "" && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the optimal string alignment distance between a and b: the number of insertions,
+// deletions, substitutions and transpositions of adjacent characters that turn a into b
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	rows := make([][]int, len(x)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(y)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(x)][len(y)]
+}
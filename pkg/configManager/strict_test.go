@@ -0,0 +1,63 @@
+package configManager
+
+import (
+	"io"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type strictConfig struct {
+	URL   string   `env:"STRICT_DB_URL"`
+	Port  int      `env:"STRICT_DB_PORT" default:"5432"`
+	Hosts []string `env:"STRICT_HOSTS"`
+}
+
+func TestUnmarshal_StrictKeys(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.yaml":     {Data: []byte("strict:\n  hosts:\n    - a\n    - b\n  cache:\n    ttl: 60\n")},
+		"configs/local.env": {Data: []byte("STRICT_DB_ULR=postgres://localhost\nSTRICT_DB_PORT=5433\n")},
+	}
+	os.Setenv("STRICT_FROM_ENV", "ignored")
+	defer func() {
+		for _, key := range []string{"STRICT_HOSTS", "STRICT_HOSTS_0", "STRICT_HOSTS_1", "STRICT_CACHE_TTL", "STRICT_DB_ULR", "STRICT_DB_PORT", "STRICT_FROM_ENV"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys), WithLogOutput(io.Discard))
+
+	var cfg strictConfig
+	assert.NoError(t, config.Unmarshal(&cfg), "unknown keys should only be reported in strict mode")
+
+	err := config.Unmarshal(&cfg, WithStrictKeys())
+	assert.EqualError(t, err, ""+
+		"unknown key STRICT_CACHE_TTL from file (configs/.yaml)\n"+
+		"unknown key STRICT_DB_ULR from file (configs/local.env, profile local), did you mean STRICT_DB_URL?")
+	if unknownKeys, ok := err.(*UnknownKeysError); assert.True(t, ok, "strict mode should return an *UnknownKeysError") {
+		assert.Equal(t, "STRICT_DB_URL", unknownKeys.Keys[1].Suggestion)
+	}
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts, "the target should still be filled in")
+	assert.Equal(t, 5433, cfg.Port)
+
+	err = config.Unmarshal(&cfg, WithStrictKeys("STRICT_DB"))
+	assert.EqualError(t, err, "unknown key STRICT_DB_ULR from file (configs/local.env, profile local), did you mean STRICT_DB_URL?", "only keys below the prefixes should be checked")
+
+	var db struct {
+		URL  string `env:"DB_ULR"`
+		Port int    `env:"DB_PORT"`
+	}
+	assert.NoError(t, config.Sub("STRICT").Unmarshal(&db, WithStrictKeys("DB")), "prefixes should be relative to the view")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("DB_URL", "DB_URL"))
+	assert.Equal(t, 1, editDistance("DB_ULR", "DB_URL"), "adjacent transpositions should count once")
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 2, editDistance("PORT", "HOST"))
+
+	assert.Equal(t, "DB_URL", suggest("db_ulr", []string{"DB_PORT", "DB_URL"}))
+	assert.Equal(t, "", suggest("PORT", []string{"HOST"}), "distant keys should not be suggested")
+}
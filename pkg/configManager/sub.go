@@ -0,0 +1,56 @@
+package configManager
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Sub returns a view of the configuration below prefix, e.g. Sub("KAFKA") reads KAFKA_BROKERS as
+// BROKERS. The view shares the store, provenance, change callbacks and reloads of its parent; GetConfig,
+// GetConfigWithDefault, Unmarshal, Origin, History, Provenance and OnChange all take keys relative to the
+// prefix. Sub views can be nested
+func (cm *Config) Sub(prefix string) *Config {
+	sub := *cm
+	sub.prefix = cm.qualify(prefix)
+	return &sub
+}
+
+// Prefix returns the prefix of a Sub view, or "" for the root Config
+func (cm *Config) Prefix() string {
+	return cm.prefix
+}
+
+// qualify turns a key relative to the view into a full, normalized key
+func (cm *Config) qualify(key string) string {
+	if cm.prefix == "" {
+		return cm.normalizeKey(key)
+	}
+	if cm.normalizer != nil {
+		return cm.normalizer.Normalize(cm.prefix, key)
+	}
+	return cm.prefix + "_" + key
+}
+
+// unqualified returns a key relative to the view as written, without normalization
+func (cm *Config) unqualified(key string) string {
+	if cm.prefix == "" {
+		return key
+	}
+	return cm.prefix + "_" + key
+}
+
+// trimPrefix returns key relative to prefix, if key lies below it. The prefix must be followed by a
+// separator such as '_' or '.', so that KAFKA does not match KAFKAESQUE
+func trimPrefix(prefix, key string) (string, bool) {
+	if prefix == "" {
+		return key, true
+	}
+	if len(key) <= len(prefix)+1 || !strings.EqualFold(key[:len(prefix)], prefix) {
+		return "", false
+	}
+	separator := rune(key[len(prefix)])
+	if unicode.IsLetter(separator) || unicode.IsDigit(separator) {
+		return "", false
+	}
+	return key[len(prefix)+1:], true
+}
@@ -0,0 +1,76 @@
+package configManager
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSub(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.yaml": {Data: []byte("kafka:\n  brokers: [a, b]\n  consumer:\n    group: billing\nkafkaesque: nope\n")},
+	}
+	defer func() {
+		for _, key := range []string{"KAFKA_BROKERS", "KAFKA_BROKERS_0", "KAFKA_BROKERS_1", "KAFKA_CONSUMER_GROUP", "KAFKAESQUE"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	config := New(WithFS(fsys))
+	kafka := config.Sub("KAFKA")
+
+	assert.Equal(t, "KAFKA", kafka.Prefix())
+	assert.Equal(t, "a,b", kafka.GetConfig("BROKERS"), "keys should be relative to the prefix")
+	assert.Equal(t, "billing", kafka.Sub("CONSUMER").GetConfig("GROUP"), "Sub views should nest")
+	assert.Equal(t, "fallback", kafka.GetConfigWithDefault("MISSING", "fallback"))
+
+	var cfg struct {
+		Brokers  []string `env:"BROKERS"`
+		Consumer struct {
+			Group string `env:"CONSUMER_GROUP" required:"true"`
+		}
+	}
+	assert.NoError(t, kafka.Unmarshal(&cfg), "Unmarshal should not return an error")
+	assert.Equal(t, []string{"a", "b"}, cfg.Brokers, "Unmarshal should bind relative to the prefix")
+	assert.Equal(t, "billing", cfg.Consumer.Group, "nested structs should keep the prefix")
+
+	origin, found := kafka.Origin("CONSUMER_GROUP")
+	assert.True(t, found, "Origin should take relative keys")
+	assert.Equal(t, "configs/.yaml", origin.Path)
+
+	provenance := kafka.Provenance()
+	assert.Contains(t, provenance, "BROKERS", "Provenance should use relative keys")
+	assert.NotContains(t, provenance, "ESQUE", "keys that only share the prefix as a substring should be left out")
+}
+
+func TestOnChange(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/.env": {Data: []byte("CHANGE_TEST_KAFKA_BROKERS=a\nCHANGE_TEST_NAME=app\n")},
+	}
+	defer func() {
+		os.Unsetenv("CHANGE_TEST_KAFKA_BROKERS")
+		os.Unsetenv("CHANGE_TEST_NAME")
+	}()
+
+	config := New(WithFS(fsys))
+
+	var all, scoped []Change
+	config.OnChange(func(change Change) { all = append(all, change) })
+	config.Sub("CHANGE_TEST_KAFKA").OnChange(func(change Change) { scoped = append(scoped, change) })
+
+	assert.NoError(t, config.LoadConfigs("configs"))
+	assert.Empty(t, all, "reloading unchanged values should not notify")
+
+	fsys["configs/.env"] = &fstest.MapFile{Data: []byte("CHANGE_TEST_KAFKA_BROKERS=b\nCHANGE_TEST_NAME=renamed\n")}
+	assert.NoError(t, config.LoadConfigs("configs"))
+
+	assert.Len(t, all, 2, "the root Config should see every change")
+	if assert.Len(t, scoped, 1, "a Sub view should only see changes below its prefix") {
+		assert.Equal(t, "BROKERS", scoped[0].Key)
+		assert.Equal(t, "a", scoped[0].OldValue)
+		assert.Equal(t, "b", scoped[0].NewValue)
+		assert.Equal(t, "configs/.env", scoped[0].Origin.Path)
+	}
+}
@@ -0,0 +1,187 @@
+package configManager
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// UsageFormat selects the output format of UsageFormat
+type UsageFormat int
+
+const (
+	// UsageText is a plain-text table with aligned columns
+	UsageText UsageFormat = iota
+	// UsageMarkdown is a Markdown table, e.g. for a README
+	UsageMarkdown
+	// UsageMan is an ENVIRONMENT section in man page (roff) syntax
+	UsageMan
+)
+
+// fieldSpec describes a configuration key read by Unmarshal, as declared by the tags of a struct field
+type fieldSpec struct {
+	Key         string
+	Type        string
+	Default     string
+	Required    bool
+	Description string
+	Allowed     []string
+	Flag        string
+	goType      reflect.Type
+}
+
+// fieldSpecs walks the fields of t like Unmarshal does, nested structs included
+func (cm *Config) fieldSpecs(t reflect.Type) []fieldSpec {
+	var specs []fieldSpec
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		// Recursive call for nested structs
+		if isNested(fieldType.Type) {
+			specs = append(specs, cm.fieldSpecs(fieldType.Type)...)
+			continue
+		}
+
+		specs = append(specs, fieldSpec{
+			Key:         cm.fieldKey(fieldType),
+			Type:        fieldType.Type.String(),
+			Default:     fieldType.Tag.Get("default"),
+			Required:    fieldType.Tag.Get("required") == "true",
+			Description: fieldType.Tag.Get("desc"),
+			Allowed:     allowedValues(fieldType),
+			Flag:        fieldType.Tag.Get("flag"),
+			goType:      fieldType.Type,
+		})
+	}
+	return specs
+}
+
+// allowedValues returns the comma-separated values of the allowed tag of a field
+func allowedValues(fieldType reflect.StructField) []string {
+	tag := fieldType.Tag.Get("allowed")
+	if tag == "" {
+		return nil
+	}
+	values := strings.Split(tag, ",")
+	for i, value := range values {
+		values[i] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// Usage describes every configuration key that Unmarshal reads into target, a struct or a pointer to
+// one, as a plain-text table with the type, default, whether it is required, the allowed values, the
+// command-line flag and the description of each key
+func (cm *Config) Usage(target interface{}) string {
+	return cm.UsageFormat(target, UsageText)
+}
+
+// UsageFormat describes the configuration keys of target like Usage, in the given format
+func (cm *Config) UsageFormat(target interface{}, format UsageFormat) string {
+	t := reflect.TypeOf(target)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	specs := cm.fieldSpecs(t)
+	switch format {
+	case UsageMarkdown:
+		return markdownUsage(specs)
+	case UsageMan:
+		return manUsage(specs)
+	default:
+		return textUsage(specs)
+	}
+}
+
+func textUsage(specs []fieldSpec) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tDEFAULT\tREQUIRED\tALLOWED\tFLAG\tDESCRIPTION")
+	for _, spec := range specs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", spec.Key, spec.Type, spec.Default, yesNo(spec.Required),
+			strings.Join(spec.Allowed, ","), flagName(spec.Flag), spec.Description)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func markdownUsage(specs []fieldSpec) string {
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Required | Allowed | Flag | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, spec := range specs {
+		cells := []string{code(spec.Key), code(spec.Type), code(spec.Default), yesNo(spec.Required),
+			code(strings.Join(spec.Allowed, ", ")), code(flagName(spec.Flag)), spec.Description}
+		for i, cell := range cells {
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return b.String()
+}
+
+func manUsage(specs []fieldSpec) string {
+	var b strings.Builder
+	b.WriteString(".SH ENVIRONMENT\n")
+	for _, spec := range specs {
+		b.WriteString(".TP\n.B " + roff(spec.Key) + "\n")
+		if spec.Description != "" {
+			b.WriteString(roff(spec.Description) + "\n.br\n")
+		}
+		details := []string{"Type: " + spec.Type}
+		if spec.Default != "" {
+			details = append(details, "Default: "+spec.Default)
+		}
+		if spec.Required {
+			details = append(details, "Required")
+		}
+		if spec.Allowed != nil {
+			details = append(details, "Allowed: "+strings.Join(spec.Allowed, ", "))
+		}
+		if spec.Flag != "" {
+			details = append(details, "Flag: "+flagName(spec.Flag))
+		}
+		b.WriteString(roff(strings.Join(details, ". ")+".") + "\n")
+	}
+	return b.String()
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+func flagName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "--" + name
+}
+
+// code formats a Markdown code span, leaving empty cells empty
+func code(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + value + "`"
+}
+
+// roff escapes backslashes, hyphens and a leading dot or quote for man page output
+func roff(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\e`)
+	value = strings.ReplaceAll(value, "-", `\-`)
+	if strings.HasPrefix(value, ".") || strings.HasPrefix(value, "'") {
+		value = `\&` + value
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package configManager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type usageConfig struct {
+	DBURL    string `env:"DB_URL" flag:"db-url" desc:"database connection string" required:"true"`
+	LogLevel string `env:"LOG_LEVEL" default:"info" allowed:"debug, info, warn" desc:"log verbosity"`
+	Server   struct {
+		Port int `env:"SERVER_PORT" default:"8080"`
+	}
+}
+
+func TestUsage(t *testing.T) {
+	config := New()
+
+	expected := "" +
+		"KEY          TYPE    DEFAULT  REQUIRED  ALLOWED          FLAG      DESCRIPTION\n" +
+		"DB_URL       string           yes                        --db-url  database connection string\n" +
+		"LOG_LEVEL    string  info     no        debug,info,warn            log verbosity\n" +
+		"SERVER_PORT  int     8080     no                                   \n"
+	assert.Equal(t, expected, config.Usage(&usageConfig{}), "text usage did not match expected")
+
+	expected = "" +
+		"| Key | Type | Default | Required | Allowed | Flag | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| `DB_URL` | `string` |  | yes |  | `--db-url` | database connection string |\n" +
+		"| `LOG_LEVEL` | `string` | `info` | no | `debug, info, warn` |  | log verbosity |\n" +
+		"| `SERVER_PORT` | `int` | `8080` | no |  |  |  |\n"
+	assert.Equal(t, expected, config.UsageFormat(usageConfig{}, UsageMarkdown), "Markdown usage did not match expected")
+
+	expected = "" +
+		".SH ENVIRONMENT\n" +
+		".TP\n.B DB_URL\ndatabase connection string\n.br\nType: string. Required. Flag: \\-\\-db\\-url.\n" +
+		".TP\n.B LOG_LEVEL\nlog verbosity\n.br\nType: string. Default: info. Allowed: debug, info, warn.\n" +
+		".TP\n.B SERVER_PORT\nType: int. Default: 8080.\n"
+	assert.Equal(t, expected, config.UsageFormat(&usageConfig{}, UsageMan), "man usage did not match expected")
+
+	assert.Equal(t, "", config.Usage("not a struct"), "non-struct targets should have no usage")
+	assert.Contains(t, config.Sub("APP").Usage(&usageConfig{}), "APP_DB_URL", "keys should include the prefix of a Sub view")
+}
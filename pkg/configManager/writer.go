@@ -0,0 +1,58 @@
+package configManager
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/LetsFocus/configManager/pkg/configManager/env"
+	"github.com/LetsFocus/configManager/pkg/configManager/hcl"
+	"github.com/LetsFocus/configManager/pkg/configManager/ini"
+	"github.com/LetsFocus/configManager/pkg/configManager/json"
+	"github.com/LetsFocus/configManager/pkg/configManager/properties"
+	"github.com/LetsFocus/configManager/pkg/configManager/yaml"
+)
+
+// writers maps file extensions to the factories of their ConfigWriter
+var writers = struct {
+	mu          sync.RWMutex
+	byExtension map[string]func() ConfigWriter
+}{byExtension: make(map[string]func() ConfigWriter)}
+
+func init() {
+	RegisterWriter([]string{".env"}, func() ConfigWriter { return &env.EnvWriter{} })
+	RegisterWriter([]string{".json"}, func() ConfigWriter { return &json.JSONWriter{} })
+	RegisterWriter([]string{".yaml", ".yml"}, func() ConfigWriter { return &yaml.YAMLWriter{} })
+	RegisterWriter([]string{".hcl"}, func() ConfigWriter { return &hcl.HCLWriter{} })
+	RegisterWriter([]string{".ini", ".cfg", ".conf"}, func() ConfigWriter { return &ini.INIWriter{} })
+	RegisterWriter([]string{".properties"}, func() ConfigWriter { return &properties.PropertiesWriter{} })
+}
+
+// RegisterWriter registers a writer factory for the given file extensions, replacing any writer
+// registered for them before
+func RegisterWriter(extensions []string, factory func() ConfigWriter) {
+	writers.mu.Lock()
+	defer writers.mu.Unlock()
+	for _, ext := range extensions {
+		writers.byExtension[strings.ToLower(ext)] = factory
+	}
+}
+
+// WriterFactory returns the writer for the longest registered extension that filePath ends with
+func WriterFactory(filePath string) (ConfigWriter, error) {
+	writers.mu.RLock()
+	defer writers.mu.RUnlock()
+
+	lower := strings.ToLower(filePath)
+	var match func() ConfigWriter
+	matchLength := 0
+	for ext, factory := range writers.byExtension {
+		if strings.HasSuffix(lower, ext) && len(ext) > matchLength {
+			match, matchLength = factory, len(ext)
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("unsupported file type: %s", filePath)
+	}
+	return match(), nil
+}
@@ -0,0 +1,74 @@
+package configManager
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriters_RoundTrip(t *testing.T) {
+	tests := []struct {
+		file string
+		// flat formats write lists of scalars comma-joined, without the indexed keys
+		joinedLists bool
+		// .env files trim values, so values with surrounding whitespace cannot be written
+		trimsValues bool
+	}{
+		{file: "config.env", trimsValues: true},
+		{file: "config.json"},
+		{file: "config.yaml"},
+		{file: "config.hcl"},
+		{file: "config.ini", joinedLists: true},
+		{file: "config.properties", joinedLists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			values := map[string]interface{}{
+				"name":    "app",
+				"debug":   true,
+				"ratio":   0.5,
+				"backup":  nil,
+				"db":      map[string]interface{}{"host": "localhost", "port": int64(5432), "primary": map[string]interface{}{"zone": "eu"}},
+				"hosts":   []interface{}{"a", "b"},
+				"servers": []interface{}{map[string]interface{}{"name": "primary"}, map[string]interface{}{"name": "replica"}},
+			}
+			expected := map[string]string{
+				"NAME":            "app",
+				"DEBUG":           "true",
+				"RATIO":           "0.5",
+				"DB_HOST":         "localhost",
+				"DB_PORT":         "5432",
+				"DB_PRIMARY_ZONE": "eu",
+				"HOSTS":           "a,b",
+				"HOSTS_0":         "a",
+				"HOSTS_1":         "b",
+				"SERVERS_0_NAME":  "primary",
+				"SERVERS_1_NAME":  "replica",
+			}
+			if tt.joinedLists {
+				delete(expected, "HOSTS_0")
+				delete(expected, "HOSTS_1")
+			}
+			if !tt.trimsValues {
+				values["padded"] = " spaced value "
+				expected["PADDED"] = " spaced value "
+			}
+
+			root, err := tree.FromValue(values)
+			assert.NoError(t, err, "Did not expect an error but got one")
+			writer, err := WriterFactory(tt.file)
+			assert.NoError(t, err, "Did not expect an error but got one")
+			var out bytes.Buffer
+			assert.NoError(t, writer.Write(&out, root), "Did not expect an error but got one")
+
+			loader, err := LoaderFactory(tt.file)
+			assert.NoError(t, err, "Did not expect an error but got one")
+			result, err := loader.(ReaderLoader).LoadReader(&out)
+			assert.NoError(t, err, "Did not expect an error but got one")
+			assert.Equal(t, expected, result, "loading the written file should yield the same keys")
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package yaml
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// decodeTree decodes a YAML document whose top level must be a mapping (or empty, which yields an empty tree)
+func decodeTree(content []byte, fileName string) (*tree.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return tree.NewMap(tree.Position{File: fileName}), nil
+	}
+
+	root, err := convert(document.Content[0], fileName)
+	if err != nil {
+		return nil, err
+	}
+	switch root.Kind {
+	case tree.NullNode:
+		return tree.NewMap(root.Pos), nil
+	case tree.MapNode:
+		return root, nil
+	default:
+		return nil, fmt.Errorf("%s: configuration must be a mapping at the top level", root.Pos)
+	}
+}
+
+// convert turns a yaml.Node into a tree.Node, resolving aliases and merge keys
+func convert(node *yaml.Node, fileName string) (*tree.Node, error) {
+	pos := tree.Position{File: fileName, Line: node.Line, Column: node.Column}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return convert(node.Content[0], fileName)
+	case yaml.AliasNode:
+		return convert(node.Alias, fileName)
+	case yaml.MappingNode:
+		result := tree.NewMap(pos)
+		var merged []*tree.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child, err := convert(value, fileName)
+			if err != nil {
+				return nil, err
+			}
+			if key.Tag == "!!merge" {
+				merged = append(merged, child)
+				continue
+			}
+			result.Set(key.Value, child)
+		}
+		// Keys from merged mappings never override keys set explicitly, whatever their order
+		for _, source := range merged {
+			if err := merge(result, source); err != nil {
+				return nil, fmt.Errorf("%s: %v", pos, err)
+			}
+		}
+		return result, nil
+	case yaml.SequenceNode:
+		result := tree.NewList(pos)
+		for _, item := range node.Content {
+			child, err := convert(item, fileName)
+			if err != nil {
+				return nil, err
+			}
+			result.Items = append(result.Items, child)
+		}
+		return result, nil
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%s: %v", pos, err)
+		}
+		return tree.NewScalar(value, pos), nil
+	}
+}
+
+// merge copies the keys of a merged mapping, or of every mapping in a merged sequence, into target
+func merge(target, source *tree.Node) error {
+	switch source.Kind {
+	case tree.MapNode:
+		for _, key := range source.Keys {
+			if _, exists := target.Children[key]; !exists {
+				target.Set(key, source.Children[key])
+			}
+		}
+		return nil
+	case tree.ListNode:
+		for _, item := range source.Items {
+			if err := merge(target, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("merge key must refer to a mapping")
+	}
+}
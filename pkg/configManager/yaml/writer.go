@@ -0,0 +1,66 @@
+package yaml
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// YAMLWriter writes configuration as a YAML document
+type YAMLWriter struct{}
+
+// Write encodes the tree as YAML, keeping the order of keys and the types of values
+func (y *YAMLWriter) Write(w io.Writer, root *tree.Node) error {
+	document, err := encodeNode(root)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// encodeNode converts a tree node into a YAML node
+func encodeNode(node *tree.Node) (*yaml.Node, error) {
+	switch node.Kind {
+	case tree.MapNode:
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range node.Keys {
+			value, err := encodeNode(node.Children[key])
+			if err != nil {
+				return nil, err
+			}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+		}
+		return mapping, nil
+	case tree.ListNode:
+		sequence := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range node.Items {
+			value, err := encodeNode(item)
+			if err != nil {
+				return nil, err
+			}
+			sequence.Content = append(sequence.Content, value)
+		}
+		return sequence, nil
+	case tree.NullNode:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	default:
+		// Numbers too large for int64 and float64 are kept as written
+		if number, ok := node.Value.(json.Number); ok {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: number.String()}, nil
+		}
+		scalar := &yaml.Node{}
+		if err := scalar.Encode(node.Value); err != nil {
+			return nil, err
+		}
+		return scalar, nil
+	}
+}
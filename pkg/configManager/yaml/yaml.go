@@ -1,26 +1,105 @@
 package yaml
 
 import (
-	"gopkg.in/yaml.v3"
-	"io/ioutil"
+	"io"
+	"io/fs"
 
 	"github.com/LetsFocus/configManager/internal"
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
+	"github.com/LetsFocus/configManager/pkg/keys"
+	"github.com/LetsFocus/configManager/pkg/tree"
 )
 
 // YAMLLoader implements ConfigLoader for .yaml files
-type YAMLLoader struct{}
+type YAMLLoader struct {
+	normalizer keys.Normalizer
+	fsys       fs.FS
+	schema     *jsonschema.Schema
+}
+
+// SetKeyNormalizer sets the normalizer applied to key paths. Keys are uppercased and joined with '_' when
+// none is set
+func (y *YAMLLoader) SetKeyNormalizer(normalizer keys.Normalizer) {
+	y.normalizer = normalizer
+}
+
+// SetFS makes Load and include directives read files from fsys instead of the local disk
+func (y *YAMLLoader) SetFS(fsys fs.FS) {
+	y.fsys = fsys
+}
 
-// Load parses YAML files and returns key-value pairs
+// SetSchema makes Load, LoadReader and LoadTree validate the decoded document, after includes are merged,
+// against schema. Nil disables validation
+func (y *YAMLLoader) SetSchema(schema *jsonschema.Schema) {
+	y.schema = schema
+}
+
+// Load parses YAML files and returns key-value pairs. A top-level `include` or `$import` key pulls in
+// other files, see LoadTree
 func (y *YAMLLoader) Load(filePath string) (map[string]string, error) {
-	content, err := ioutil.ReadFile(filePath)
+	content, err := internal.FilesFor(y.fsys).ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return y.parse(content, filePath)
+}
+
+// LoadReader parses YAML content from a reader and returns key-value pairs
+func (y *YAMLLoader) LoadReader(r io.Reader) (map[string]string, error) {
+	content, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	var data map[string]interface{}
-	err = yaml.Unmarshal(content, &data)
+
+	return y.parse(content, "")
+}
+
+// LoadTree parses YAML content from a reader into a tree that keeps value types and source positions.
+// Files named by a top-level `include` or `$import` key, relative to fileName and possibly glob patterns,
+// are merged in declared order underneath the content of the file itself
+func (y *YAMLLoader) LoadTree(r io.Reader, fileName string) (*tree.Node, error) {
+	content, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
-	return internal.FlattenMap(data, ""), nil
+	return y.decode(content, fileName)
+}
+
+// decode parses YAML content into a tree and resolves its include directives. The result is validated against
+// the schema, if one is set
+func (y *YAMLLoader) decode(content []byte, fileName string) (*tree.Node, error) {
+	root, err := decodeTree(content, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err = internal.ResolveIncludes(root, fileName, internal.FilesFor(y.fsys), decodeTree)
+	if err != nil {
+		return nil, err
+	}
+
+	if y.schema != nil {
+		if err := y.schema.Validate(root); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// parse decodes YAML content and flattens it into key-value pairs
+func (y *YAMLLoader) parse(content []byte, fileName string) (map[string]string, error) {
+	root, err := y.decode(content, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := y.normalizer
+	if normalizer == nil {
+		normalizer = keys.Upper
+	}
+
+	configs, _, err := internal.FlattenTree(root, normalizer)
+	return configs, err
 }
@@ -2,8 +2,11 @@ package yaml
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/LetsFocus/configManager/pkg/jsonschema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +42,30 @@ parent:
 			},
 			expectError: false,
 		},
+		{
+			name: "Valid YAML with lists, nulls and typed scalars",
+			fileContent: `
+hosts:
+  - alpha
+  - beta
+replicas:
+  - name: primary
+    port: 5432
+timeout: ~
+max_size: 18446744073709551615
+started: 2024-01-02T03:04:05Z
+`,
+			expected: map[string]string{
+				"HOSTS":           "alpha,beta",
+				"HOSTS_0":         "alpha",
+				"HOSTS_1":         "beta",
+				"REPLICAS_0_NAME": "primary",
+				"REPLICAS_0_PORT": "5432",
+				"MAX_SIZE":        "18446744073709551615",
+				"STARTED":         "2024-01-02T03:04:05Z",
+			},
+			expectError: false,
+		},
 		{
 			name:        "Invalid YAML content",
 			fileContent: `: invalid YAML`,
@@ -76,3 +103,53 @@ parent:
 		})
 	}
 }
+
+func TestYAMLLoader_LoadReader(t *testing.T) {
+	loader := &YAMLLoader{}
+	result, err := loader.LoadReader(strings.NewReader("db:\n  host: localhost\n"))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"DB_HOST": "localhost"}, result, "Loaded configuration did not match expected")
+}
+
+func TestYAMLLoader_LoadTree(t *testing.T) {
+	loader := &YAMLLoader{}
+	content := "base: &base\n  port: 8080\nservers:\n  - <<: *base\n    name: primary\n"
+	root, err := loader.LoadTree(strings.NewReader(content), "config.yaml")
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	server := root.Children["servers"].Items[0]
+	assert.Equal(t, "primary", server.Children["name"].Value, "explicit keys should be kept")
+	assert.Equal(t, 8080, server.Children["port"].Value, "merge keys should be resolved")
+	assert.Equal(t, "config.yaml:5:11", server.Children["name"].Pos.String(), "nodes should carry their position")
+}
+
+func TestYAMLLoader_Include(t *testing.T) {
+	fsys := fstest.MapFS{
+		"configs/app.yaml":          {Data: []byte("include:\n  - common.yaml\n  - conf.d/*.yaml\ndb:\n  host: primary\n")},
+		"configs/common.yaml":       {Data: []byte("db:\n  host: localhost\n  port: 5432\nlog: info\n")},
+		"configs/conf.d/10-db.yaml": {Data: []byte("db:\n  port: 6432\n")},
+		"configs/conf.d/20-log.yml": {Data: []byte("log: debug\n")},
+		"configs/loop.yaml":         {Data: []byte("$import: loop.yaml\n")},
+	}
+
+	loader := &YAMLLoader{}
+	loader.SetFS(fsys)
+	result, err := loader.Load("configs/app.yaml")
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"DB_HOST": "primary", "DB_PORT": "6432", "LOG": "info"}, result, "includes should be merged in declared order")
+
+	_, err = loader.Load("configs/loop.yaml")
+	assert.EqualError(t, err, "include cycle: configs/loop.yaml -> configs/loop.yaml")
+}
+
+func TestYAMLLoader_SetSchema(t *testing.T) {
+	loader := &YAMLLoader{}
+	loader.SetSchema(jsonschema.MustCompile([]byte(`{"properties": {"db": {"properties": {"port": {"type": "integer"}}}}}`)))
+
+	_, err := loader.LoadTree(strings.NewReader("db:\n  host: localhost\n  port: \"5432\"\n"), "config.yaml")
+	assert.EqualError(t, err, "config.yaml:3:9: /db/port: expected integer, got string", "errors should carry the pointer and position")
+
+	result, err := loader.LoadReader(strings.NewReader("db:\n  port: 5432\n"))
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, map[string]string{"DB_PORT": "5432"}, result, "valid documents should be flattened")
+}
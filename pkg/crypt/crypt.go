@@ -0,0 +1,194 @@
+// Package crypt encrypts individual configuration values so that files holding secrets can be committed.
+//
+// Encrypted values are envelopes of the form ENC[aes256-gcm,<base64>], where the base64 text holds a random
+// 12-byte nonce followed by the AES-256-GCM ciphertext and tag. Values encrypted from a number, boolean or
+// timestamp rather than a string add their type, e.g. ENC[aes256-gcm,<base64>,type:integer], so that
+// decrypting restores it. The flattened key name and the type are authenticated with the ciphertext, so an
+// envelope cannot be moved to another key. Keys are 32 random bytes, written as base64 or hex, e.g. the
+// output of `openssl rand -base64 32`.
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// Algorithm names the cipher in envelopes
+	Algorithm = "aes256-gcm"
+
+	// DefaultKeyEnv is the environment variable holding the key when none is configured. DefaultKeyEnv
+	// with a _FILE suffix may name a file holding the key instead
+	DefaultKeyEnv = "CONFIGMANAGER_KEY"
+
+	prefix     = "ENC["
+	suffix     = "]"
+	typePrefix = "type:"
+)
+
+// Types of the scalars a value is encrypted from. Strings have no type in the envelope
+const (
+	TypeString    = ""
+	TypeInteger   = "integer"
+	TypeNumber    = "number"
+	TypeBoolean   = "boolean"
+	TypeTimestamp = "timestamp"
+)
+
+// ErrNoKey is returned by KeyFromEnv when neither the variable nor its _FILE variant is set
+var ErrNoKey = errors.New("no encryption key is configured")
+
+// Key is a 256-bit AES key
+type Key []byte
+
+// ParseKey decodes a key written as base64 or hex
+func ParseKey(text string) (Key, error) {
+	text = strings.TrimSpace(text)
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(text); err == nil && len(key) == 32 {
+			return key, nil
+		}
+	}
+	return nil, errors.New("invalid encryption key: expected 32 bytes as base64 or hex")
+}
+
+// ReadKeyFile reads a key from a file, ignoring surrounding whitespace
+func ReadKeyFile(path string) (Key, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read encryption key: %v", err)
+	}
+	key, err := ParseKey(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return key, nil
+}
+
+// KeyFromEnv reads a key from the environment variable name or, if it is not set, from the file named by
+// name_FILE. It returns ErrNoKey if neither is set
+func KeyFromEnv(name string) (Key, error) {
+	if text, found := os.LookupEnv(name); found && text != "" {
+		key, err := ParseKey(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return key, nil
+	}
+	if path, found := os.LookupEnv(name + "_FILE"); found && path != "" {
+		return ReadKeyFile(path)
+	}
+	return nil, ErrNoKey
+}
+
+// GenerateKey returns a new random key
+func GenerateKey() (Key, error) {
+	key := make(Key, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// String encodes the key as base64
+func (k Key) String() string {
+	return base64.StdEncoding.EncodeToString(k)
+}
+
+// IsEncrypted reports whether value is an envelope
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
+
+// Encrypt seals plaintext, the value of the flattened key name (e.g. DB_PASSWORD) with the given type, into
+// an envelope with a random nonce
+func Encrypt(key Key, name, plaintext, valueType string) (string, error) {
+	if !validType(valueType) {
+		return "", fmt.Errorf("unsupported value type %q", valueType)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), associatedData(name, valueType))
+
+	envelope := prefix + Algorithm + "," + base64.StdEncoding.EncodeToString(sealed)
+	if valueType != TypeString {
+		envelope += "," + typePrefix + valueType
+	}
+	return envelope + suffix, nil
+}
+
+// Decrypt opens an envelope of the flattened key name and returns the plaintext and its type. It fails if
+// the value is not an envelope, uses another algorithm, or was not encrypted with key for name
+func Decrypt(key Key, name, value string) (string, string, error) {
+	if !IsEncrypted(value) {
+		return "", "", errors.New("value is not encrypted")
+	}
+	fields := strings.Split(value[len(prefix):len(value)-len(suffix)], ",")
+	if len(fields) < 2 || fields[0] != Algorithm {
+		return "", "", fmt.Errorf("unsupported encryption %q, expected %s", fields[0], Algorithm)
+	}
+	valueType := TypeString
+	if len(fields) == 3 && strings.HasPrefix(fields[2], typePrefix) {
+		valueType = fields[2][len(typePrefix):]
+	}
+	if len(fields) > 3 || (len(fields) == 3 && valueType == TypeString) || !validType(valueType) {
+		return "", "", errors.New("invalid encrypted value: unknown fields")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid encrypted value: %v", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", "", err
+	}
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return "", "", errors.New("invalid encrypted value: too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], associatedData(name, valueType))
+	if err != nil {
+		return "", "", errors.New("cannot decrypt value: wrong key, value of another key or corrupted data")
+	}
+	return string(plaintext), valueType, nil
+}
+
+// associatedData binds an envelope to its key name, in upper case, and to its type
+func associatedData(name, valueType string) []byte {
+	return []byte(strings.ToUpper(name) + "\x00" + valueType)
+}
+
+func validType(valueType string) bool {
+	switch valueType {
+	case TypeString, TypeInteger, TypeNumber, TypeBoolean, TypeTimestamp:
+		return true
+	}
+	return false
+}
+
+func newAEAD(key Key) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.New("invalid encryption key: expected 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,90 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NoError(t, err, "Did not expect an error but got one")
+
+	encrypted, err := Encrypt(key, "DB_PASSWORD", "s3cret", TypeString)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.True(t, strings.HasPrefix(encrypted, "ENC[aes256-gcm,"), "values should be wrapped in an envelope")
+	assert.NotContains(t, encrypted, "type:", "strings should have no type")
+	assert.True(t, IsEncrypted(encrypted))
+
+	again, _ := Encrypt(key, "DB_PASSWORD", "s3cret", TypeString)
+	assert.NotEqual(t, encrypted, again, "every encryption should use a new nonce")
+
+	plaintext, valueType, err := Decrypt(key, "db_password", encrypted)
+	assert.NoError(t, err, "key names should be compared in upper case")
+	assert.Equal(t, "s3cret", plaintext)
+	assert.Equal(t, TypeString, valueType)
+
+	port, err := Encrypt(key, "DB_PORT", "5432", TypeInteger)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.True(t, strings.HasSuffix(port, ",type:integer]"), "the type should be kept in the envelope")
+	plaintext, valueType, err = Decrypt(key, "DB_PORT", port)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	assert.Equal(t, "5432", plaintext)
+	assert.Equal(t, TypeInteger, valueType)
+
+	_, _, err = Decrypt(key, "DB_USER", encrypted)
+	assert.EqualError(t, err, "cannot decrypt value: wrong key, value of another key or corrupted data", "envelopes should be bound to their key")
+	_, _, err = Decrypt(key, "DB_PORT", strings.Replace(port, "type:integer", "type:boolean", 1))
+	assert.EqualError(t, err, "cannot decrypt value: wrong key, value of another key or corrupted data", "the type should be authenticated")
+	other, _ := GenerateKey()
+	_, _, err = Decrypt(other, "DB_PASSWORD", encrypted)
+	assert.EqualError(t, err, "cannot decrypt value: wrong key, value of another key or corrupted data")
+
+	_, err = Encrypt(key, "DB_PORT", "5432", "int")
+	assert.EqualError(t, err, `unsupported value type "int"`)
+	_, _, err = Decrypt(key, "DB_PASSWORD", "plain")
+	assert.EqualError(t, err, "value is not encrypted")
+	_, _, err = Decrypt(key, "DB_PASSWORD", "ENC[age,abc]")
+	assert.EqualError(t, err, `unsupported encryption "age", expected aes256-gcm`)
+	_, _, err = Decrypt(key, "DB_PASSWORD", "ENC[aes256-gcm,AAAA]")
+	assert.EqualError(t, err, "invalid encrypted value: too short")
+	_, _, err = Decrypt(key, "DB_PASSWORD", "ENC[aes256-gcm,AAAA,type:int]")
+	assert.EqualError(t, err, "invalid encrypted value: unknown fields")
+}
+
+func TestParseKey(t *testing.T) {
+	key, _ := GenerateKey()
+
+	parsed, err := ParseKey(key.String() + "\n")
+	assert.NoError(t, err, "base64 keys should be accepted")
+	assert.Equal(t, key, parsed)
+
+	parsed, err = ParseKey(hex.EncodeToString(key))
+	assert.NoError(t, err, "hex keys should be accepted")
+	assert.Equal(t, key, parsed)
+
+	_, err = ParseKey("too short")
+	assert.EqualError(t, err, "invalid encryption key: expected 32 bytes as base64 or hex")
+}
+
+func TestKeyFromEnv(t *testing.T) {
+	key, _ := GenerateKey()
+	path := filepath.Join(t.TempDir(), "key")
+	os.WriteFile(path, []byte(key.String()+"\n"), 0600)
+
+	_, err := KeyFromEnv("CRYPT_TEST_KEY")
+	assert.Equal(t, ErrNoKey, err, "a missing key should be reported as ErrNoKey")
+
+	t.Setenv("CRYPT_TEST_KEY_FILE", path)
+	loaded, err := KeyFromEnv("CRYPT_TEST_KEY")
+	assert.NoError(t, err, "the key should be read from the file named by the _FILE variable")
+	assert.Equal(t, key, loaded)
+
+	t.Setenv("CRYPT_TEST_KEY", "invalid")
+	_, err = KeyFromEnv("CRYPT_TEST_KEY")
+	assert.EqualError(t, err, "CRYPT_TEST_KEY: invalid encryption key: expected 32 bytes as base64 or hex", "the variable should take precedence over the file")
+}
@@ -0,0 +1,431 @@
+// Package jsonschema validates configuration documents against a JSON Schema before they are flattened.
+//
+// It implements the validation keywords of draft 2020-12 that describe configuration files: type, enum,
+// const, properties, required, additionalProperties, patternProperties, propertyNames, min/maxProperties,
+// items, prefixItems, min/maxItems, uniqueItems, contains, min/maxLength, pattern, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not and $ref to a JSON pointer in
+// the same document such as "#/$defs/port". Annotations such as title, description, default and format are
+// ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	// boolean is set for the schemas true and false
+	boolean *bool
+
+	types    []string
+	enum     []interface{}
+	constant interface{}
+	hasConst bool
+
+	properties        map[string]*Schema
+	required          []string
+	additional        *Schema
+	patternProperties []patternSchema
+	propertyNames     *Schema
+	minProperties     *int
+	maxProperties     *int
+
+	items       *Schema
+	prefixItems []*Schema
+	contains    *Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+
+	ref      string
+	resolved *Schema
+}
+
+// patternSchema applies a schema to the properties whose names match a regular expression
+type patternSchema struct {
+	pattern *regexp.Regexp
+	schema  *Schema
+}
+
+// compileError is an invalid keyword at a JSON pointer of the schema document
+type compileError struct {
+	path    string
+	message string
+}
+
+func (e *compileError) Error() string {
+	return fmt.Sprintf("invalid JSON Schema at %s: %s", display(e.path), e.message)
+}
+
+// compiler compiles the schemas of one document, keeping every schema by its JSON pointer so that $ref
+// can be resolved once the whole document is compiled
+type compiler struct {
+	document interface{}
+	byPath   map[string]*Schema
+	refs     []*Schema
+}
+
+// Compile parses a JSON Schema document
+func Compile(data []byte) (*Schema, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %v", err)
+	}
+
+	c := &compiler{document: document, byPath: make(map[string]*Schema)}
+	root, err := c.compile(document, "")
+	if err != nil {
+		return nil, err
+	}
+	// resolving a reference may compile further schemas, which may hold references of their own
+	for i := 0; i < len(c.refs); i++ {
+		if err := c.resolve(c.refs[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.checkCycles(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// MustCompile is like Compile but panics if the schema is invalid, for schemas that are part of the program
+func MustCompile(data []byte) *Schema {
+	schema, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func (c *compiler) compile(value interface{}, path string) (*Schema, error) {
+	if schema, exists := c.byPath[path]; exists {
+		return schema, nil
+	}
+
+	schema := &Schema{}
+	c.byPath[path] = schema
+	switch v := value.(type) {
+	case bool:
+		schema.boolean = &v
+		return schema, nil
+	case map[string]interface{}:
+		if err := c.keywords(schema, v, path); err != nil {
+			if _, nested := err.(*compileError); !nested {
+				err = &compileError{path: path, message: err.Error()}
+			}
+			return nil, err
+		}
+		return schema, nil
+	default:
+		return nil, &compileError{path: path, message: "schema must be an object or a boolean"}
+	}
+}
+
+// keywords compiles the keywords of a schema object
+func (c *compiler) keywords(schema *Schema, object map[string]interface{}, path string) error {
+	var err error
+	sub := func(keyword string, value interface{}) (*Schema, error) {
+		return c.compile(value, path+"/"+escape(keyword))
+	}
+	subList := func(keyword string, value interface{}) ([]*Schema, error) {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array", keyword)
+		}
+		schemas := make([]*Schema, 0, len(list))
+		for i, item := range list {
+			itemSchema, err := c.compile(item, path+"/"+keyword+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			schemas = append(schemas, itemSchema)
+		}
+		return schemas, nil
+	}
+
+	for keyword, value := range object {
+		switch keyword {
+		case "type":
+			switch v := value.(type) {
+			case string:
+				schema.types = []string{v}
+			case []interface{}:
+				for _, item := range v {
+					name, ok := item.(string)
+					if !ok {
+						return errors.New("type must be a string or an array of strings")
+					}
+					schema.types = append(schema.types, name)
+				}
+			default:
+				return errors.New("type must be a string or an array of strings")
+			}
+		case "enum":
+			list, ok := value.([]interface{})
+			if !ok {
+				return errors.New("enum must be an array")
+			}
+			for _, item := range list {
+				schema.enum = append(schema.enum, normalize(item))
+			}
+		case "const":
+			schema.constant, schema.hasConst = normalize(value), true
+		case "properties", "$defs", "definitions":
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s must be an object", keyword)
+			}
+			compiled := make(map[string]*Schema, len(object))
+			for name, property := range object {
+				if compiled[name], err = c.compile(property, path+"/"+escape(keyword)+"/"+escape(name)); err != nil {
+					return err
+				}
+			}
+			if keyword == "properties" {
+				schema.properties = compiled
+			}
+		case "required":
+			list, ok := value.([]interface{})
+			if !ok {
+				return errors.New("required must be an array of strings")
+			}
+			for _, item := range list {
+				name, ok := item.(string)
+				if !ok {
+					return errors.New("required must be an array of strings")
+				}
+				schema.required = append(schema.required, name)
+			}
+		case "additionalProperties":
+			schema.additional, err = sub(keyword, value)
+		case "patternProperties":
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return errors.New("patternProperties must be an object")
+			}
+			for expr, property := range object {
+				pattern, err := regexp.Compile(expr)
+				if err != nil {
+					return fmt.Errorf("patternProperties: %v", err)
+				}
+				compiled, err := c.compile(property, path+"/patternProperties/"+escape(expr))
+				if err != nil {
+					return err
+				}
+				schema.patternProperties = append(schema.patternProperties, patternSchema{pattern, compiled})
+			}
+		case "propertyNames":
+			schema.propertyNames, err = sub(keyword, value)
+		case "minProperties":
+			schema.minProperties, err = count(keyword, value)
+		case "maxProperties":
+			schema.maxProperties, err = count(keyword, value)
+		case "items":
+			schema.items, err = sub(keyword, value)
+		case "prefixItems":
+			schema.prefixItems, err = subList(keyword, value)
+		case "contains":
+			schema.contains, err = sub(keyword, value)
+		case "minItems":
+			schema.minItems, err = count(keyword, value)
+		case "maxItems":
+			schema.maxItems, err = count(keyword, value)
+		case "uniqueItems":
+			schema.uniqueItems, _ = value.(bool)
+		case "minLength":
+			schema.minLength, err = count(keyword, value)
+		case "maxLength":
+			schema.maxLength, err = count(keyword, value)
+		case "pattern":
+			expr, ok := value.(string)
+			if !ok {
+				return errors.New("pattern must be a string")
+			}
+			if schema.pattern, err = regexp.Compile(expr); err != nil {
+				return fmt.Errorf("pattern: %v", err)
+			}
+		case "minimum":
+			schema.minimum, err = number(keyword, value)
+		case "maximum":
+			schema.maximum, err = number(keyword, value)
+		case "exclusiveMinimum":
+			schema.exclusiveMinimum, err = number(keyword, value)
+		case "exclusiveMaximum":
+			schema.exclusiveMaximum, err = number(keyword, value)
+		case "multipleOf":
+			if schema.multipleOf, err = number(keyword, value); err == nil && *schema.multipleOf <= 0 {
+				err = errors.New("multipleOf must be greater than 0")
+			}
+		case "allOf":
+			schema.allOf, err = subList(keyword, value)
+		case "anyOf":
+			schema.anyOf, err = subList(keyword, value)
+		case "oneOf":
+			schema.oneOf, err = subList(keyword, value)
+		case "not":
+			schema.not, err = sub(keyword, value)
+		case "$ref":
+			ref, ok := value.(string)
+			if !ok {
+				return errors.New("$ref must be a string")
+			}
+			schema.ref = ref
+			c.refs = append(c.refs, schema)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve points a schema with $ref at the schema it refers to. Only references to a JSON pointer in the
+// same document are supported
+func (c *compiler) resolve(schema *Schema) error {
+	if !strings.HasPrefix(schema.ref, "#") {
+		return fmt.Errorf("invalid JSON Schema: unsupported $ref %q, only references within the document are supported", schema.ref)
+	}
+	pointer, err := url.PathUnescape(schema.ref[1:])
+	if err != nil {
+		return fmt.Errorf("invalid JSON Schema: invalid $ref %q: %v", schema.ref, err)
+	}
+
+	target := c.document
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = unescape(token)
+			switch v := target.(type) {
+			case map[string]interface{}:
+				target = v[token]
+			case []interface{}:
+				index, err := strconv.Atoi(token)
+				if err != nil || index < 0 || index >= len(v) {
+					target = nil
+				} else {
+					target = v[index]
+				}
+			default:
+				target = nil
+			}
+			if target == nil {
+				return fmt.Errorf("invalid JSON Schema: $ref %q does not resolve", schema.ref)
+			}
+		}
+	}
+
+	schema.resolved, err = c.compile(target, pointer)
+	return err
+}
+
+// checkCycles reports a $ref that leads back to a schema applied to the same value, directly or through
+// allOf, anyOf, oneOf or not, since validating it would never end. References reached by descending into
+// a property or an item are recursive schemas and are fine
+func (c *compiler) checkCycles() error {
+	paths := make([]string, 0, len(c.byPath))
+	pathOf := make(map[*Schema]string, len(c.byPath))
+	for path, schema := range c.byPath {
+		paths = append(paths, path)
+		pathOf[schema] = path
+	}
+	sort.Strings(paths)
+
+	const visiting, visited = 1, 2
+	state := make(map[*Schema]int)
+	var visit func(schema *Schema) error
+	visit = func(schema *Schema) error {
+		switch state[schema] {
+		case visiting:
+			return &compileError{path: pathOf[schema], message: "$ref cycle applies the schema to the same value again"}
+		case visited:
+			return nil
+		}
+		state[schema] = visiting
+		next := append(append(append([]*Schema{schema.resolved, schema.not}, schema.allOf...), schema.anyOf...), schema.oneOf...)
+		for _, applied := range next {
+			if applied == nil {
+				continue
+			}
+			if err := visit(applied); err != nil {
+				return err
+			}
+		}
+		state[schema] = visited
+		return nil
+	}
+	for _, path := range paths {
+		if err := visit(c.byPath[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count decodes a non-negative integer keyword
+func count(keyword string, value interface{}) (*int, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a non-negative integer", keyword)
+	}
+	n, err := strconv.Atoi(number.String())
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative integer", keyword)
+	}
+	return &n, nil
+}
+
+// number decodes a numeric keyword
+func number(keyword string, value interface{}) (*float64, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a number", keyword)
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", keyword)
+	}
+	return &f, nil
+}
+
+// escape encodes a JSON pointer reference token
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// unescape decodes a JSON pointer reference token
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// display formats a JSON pointer for messages, where the empty pointer to the whole document is easy to miss
+func display(pointer string) string {
+	if pointer == "" {
+		return "(root)"
+	}
+	return pointer
+}
@@ -0,0 +1,118 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+// document builds a tree without positions from decoded values
+func document(t *testing.T, value interface{}) *tree.Node {
+	root, err := tree.FromValue(value)
+	assert.NoError(t, err, "Did not expect an error but got one")
+	return root
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected string
+	}{
+		{name: "Invalid JSON", schema: `{`, expected: "invalid JSON Schema: unexpected EOF"},
+		{name: "Schema of the wrong type", schema: `{"items": 1}`, expected: "invalid JSON Schema at /items: schema must be an object or a boolean"},
+		{name: "Invalid keyword", schema: `{"properties": {"a": {"minLength": -1}}}`, expected: "invalid JSON Schema at /properties/a: minLength must be a non-negative integer"},
+		{name: "Invalid pattern", schema: `{"pattern": "("}`, expected: "invalid JSON Schema at (root): pattern: error parsing regexp: missing closing ): `(`"},
+		{name: "Remote reference", schema: `{"$ref": "https://example.com/schema.json"}`, expected: `invalid JSON Schema: unsupported $ref "https://example.com/schema.json", only references within the document are supported`},
+		{name: "Unresolved reference", schema: `{"$ref": "#/$defs/missing"}`, expected: `invalid JSON Schema: $ref "#/$defs/missing" does not resolve`},
+		{name: "Reference to itself", schema: `{"$ref": "#"}`, expected: "invalid JSON Schema at (root): $ref cycle applies the schema to the same value again"},
+		{name: "Reference cycle", schema: `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`, expected: "invalid JSON Schema at /$defs/a: $ref cycle applies the schema to the same value again"},
+		{name: "Reference cycle through allOf", schema: `{"allOf": [{"$ref": "#"}]}`, expected: "invalid JSON Schema at (root): $ref cycle applies the schema to the same value again"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+
+	_, err := Compile([]byte(`{"properties": {"child": {"$ref": "#"}}}`))
+	assert.NoError(t, err, "references reached through a property should be allowed")
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := MustCompile([]byte(`{
+		"$defs": {
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"server": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {"name": {"type": "string", "minLength": 1}, "port": {"$ref": "#/$defs/port"}},
+				"additionalProperties": false
+			}
+		},
+		"type": "object",
+		"properties": {
+			"level": {"enum": ["debug", "info"]},
+			"ratio": {"type": "number", "exclusiveMaximum": 1},
+			"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "uniqueItems": true, "maxItems": 3},
+			"servers": {"type": "array", "items": {"$ref": "#/$defs/server"}, "minItems": 1},
+			"mode": {"oneOf": [{"const": "a"}, {"type": "integer"}]},
+			"labels": {"type": "object", "patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": {"type": "boolean"}}
+		}
+	}`))
+
+	valid := document(t, map[string]interface{}{
+		"level":   "info",
+		"ratio":   0.5,
+		"tags":    []interface{}{"a", "b"},
+		"servers": []interface{}{map[string]interface{}{"name": "primary", "port": int64(5432)}},
+		"mode":    float64(3),
+		"labels":  map[string]interface{}{"x-team": "core", "public": true},
+	})
+	assert.NoError(t, schema.Validate(valid), "a valid document should pass")
+	assert.True(t, schema.Valid(valid))
+
+	invalid := document(t, map[string]interface{}{
+		"level":   "trace",
+		"ratio":   1,
+		"tags":    []interface{}{"a", "a", "B", "c"},
+		"servers": []interface{}{map[string]interface{}{"port": 0, "extra": nil}},
+		"mode":    "b",
+		"labels":  map[string]interface{}{"x-team": 1, "public": "yes"},
+	})
+	err := schema.Validate(invalid)
+	if assert.IsType(t, ValidationErrors{}, err) {
+		var messages []string
+		for _, validationError := range err.(ValidationErrors) {
+			messages = append(messages, validationError.Error())
+		}
+		assert.ElementsMatch(t, []string{
+			`/level: must be one of "debug", "info"`,
+			`/ratio: must be less than 1`,
+			`/tags: must have at most 3 items`,
+			`/tags/1: duplicates an earlier item`,
+			`/tags/2: must match pattern "^[a-z]+$"`,
+			`/servers/0: missing required property "name"`,
+			`/servers/0/port: must be at least 1`,
+			`/servers/0/extra: property "extra" is not allowed`,
+			`/mode: must match exactly one schema in oneOf, matched 0`,
+			`/labels/x-team: expected string, got integer`,
+			`/labels/public: expected boolean, got string`,
+		}, messages)
+	}
+	assert.False(t, schema.Valid(invalid))
+
+	err = MustCompile([]byte(`{"type": "object"}`)).Validate(document(t, []interface{}{}))
+	assert.EqualError(t, err, "(root): expected object, got array")
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{Pointer: "/db/port", Pos: tree.Position{File: "config.yaml", Line: 3, Column: 9}, Message: "expected integer, got string"}
+	assert.Equal(t, "config.yaml:3:9: /db/port: expected integer, got string", err.Error())
+
+	err = &ValidationError{Pointer: "/a~1b", Message: "must be at least 1"}
+	assert.Equal(t, "/a~1b: must be at least 1", err.Error())
+}
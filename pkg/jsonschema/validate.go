@@ -0,0 +1,371 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/LetsFocus/configManager/pkg/tree"
+)
+
+// ValidationError is a value of a document that does not satisfy its schema
+type ValidationError struct {
+	// Pointer is the JSON pointer of the value in the document, e.g. /servers/0/port
+	Pointer string
+	// Pos is the position of the value in its source file
+	Pos     tree.Position
+	Message string
+}
+
+// Error formats the error as file:line:column: pointer: message
+func (e *ValidationError) Error() string {
+	if e.Pos.File == "" && e.Pos.Line == 0 {
+		return fmt.Sprintf("%s: %s", display(e.Pointer), e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Pos, display(e.Pointer), e.Message)
+}
+
+// ValidationErrors are all the errors found in a document, in document order
+type ValidationErrors []*ValidationError
+
+// Error lists the errors one per line
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate checks a decoded document against the schema. It returns nil or the ValidationErrors found
+func (s *Schema) Validate(root *tree.Node) error {
+	if errs := s.validate(root, ""); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Valid reports whether a document satisfies the schema
+func (s *Schema) Valid(root *tree.Node) bool {
+	return len(s.validate(root, "")) == 0
+}
+
+func (s *Schema) validate(node *tree.Node, pointer string) ValidationErrors {
+	if s.boolean != nil {
+		if *s.boolean {
+			return nil
+		}
+		return ValidationErrors{failure(node, pointer, "no value is allowed here")}
+	}
+
+	var errs ValidationErrors
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, failure(node, pointer, fmt.Sprintf(format, args...)))
+	}
+
+	if s.resolved != nil {
+		errs = append(errs, s.resolved.validate(node, pointer)...)
+	}
+
+	kind := kindOf(node)
+	if len(s.types) > 0 && !matchesType(s.types, kind) {
+		fail("expected %s, got %s", strings.Join(s.types, " or "), kind)
+		// the remaining keywords would only repeat the type mismatch
+		return errs
+	}
+
+	if s.enum != nil || s.hasConst {
+		value := normalize(node.Interface())
+		if s.hasConst && !reflect.DeepEqual(value, s.constant) {
+			fail("must be %s", format(s.constant))
+		}
+		if s.enum != nil && !contains(s.enum, value) {
+			allowed := make([]string, 0, len(s.enum))
+			for _, item := range s.enum {
+				allowed = append(allowed, format(item))
+			}
+			fail("must be one of %s", strings.Join(allowed, ", "))
+		}
+	}
+
+	switch node.Kind {
+	case tree.MapNode:
+		errs = append(errs, s.validateObject(node, pointer)...)
+	case tree.ListNode:
+		errs = append(errs, s.validateArray(node, pointer)...)
+	case tree.ScalarNode:
+		if value, ok := stringValue(node.Value); ok {
+			length := utf8.RuneCountInString(value)
+			if s.minLength != nil && length < *s.minLength {
+				fail("must be at least %d characters long", *s.minLength)
+			}
+			if s.maxLength != nil && length > *s.maxLength {
+				fail("must be at most %d characters long", *s.maxLength)
+			}
+			if s.pattern != nil && !s.pattern.MatchString(value) {
+				fail("must match pattern %q", s.pattern)
+			}
+		}
+		if value, ok := numberValue(node.Value); ok {
+			if s.minimum != nil && value < *s.minimum {
+				fail("must be at least %v", *s.minimum)
+			}
+			if s.maximum != nil && value > *s.maximum {
+				fail("must be at most %v", *s.maximum)
+			}
+			if s.exclusiveMinimum != nil && value <= *s.exclusiveMinimum {
+				fail("must be greater than %v", *s.exclusiveMinimum)
+			}
+			if s.exclusiveMaximum != nil && value >= *s.exclusiveMaximum {
+				fail("must be less than %v", *s.exclusiveMaximum)
+			}
+			if s.multipleOf != nil {
+				quotient := value / *s.multipleOf
+				if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+					fail("must be a multiple of %v", *s.multipleOf)
+				}
+			}
+		}
+	}
+
+	for _, sub := range s.allOf {
+		errs = append(errs, sub.validate(node, pointer)...)
+	}
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if len(sub.validate(node, pointer)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one schema in anyOf")
+		}
+	}
+	if len(s.oneOf) > 0 {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if len(sub.validate(node, pointer)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("must match exactly one schema in oneOf, matched %d", matched)
+		}
+	}
+	if s.not != nil && len(s.not.validate(node, pointer)) == 0 {
+		fail("must not match the schema in not")
+	}
+
+	return errs
+}
+
+// validateObject applies the object keywords to a map node
+func (s *Schema) validateObject(node *tree.Node, pointer string) ValidationErrors {
+	var errs ValidationErrors
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, failure(node, pointer, fmt.Sprintf(format, args...)))
+	}
+
+	for _, name := range s.required {
+		if _, exists := node.Children[name]; !exists {
+			fail("missing required property %q", name)
+		}
+	}
+	if s.minProperties != nil && len(node.Keys) < *s.minProperties {
+		fail("must have at least %d properties", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(node.Keys) > *s.maxProperties {
+		fail("must have at most %d properties", *s.maxProperties)
+	}
+
+	for _, key := range node.Keys {
+		child, childPointer := node.Children[key], pointer+"/"+escape(key)
+		if s.propertyNames != nil && len(s.propertyNames.validate(tree.NewScalar(key, child.Pos), childPointer)) > 0 {
+			errs = append(errs, failure(child, childPointer, fmt.Sprintf("property name %q is not allowed", key)))
+		}
+
+		matched := false
+		if property, exists := s.properties[key]; exists {
+			matched = true
+			errs = append(errs, property.validate(child, childPointer)...)
+		}
+		for _, pattern := range s.patternProperties {
+			if pattern.pattern.MatchString(key) {
+				matched = true
+				errs = append(errs, pattern.schema.validate(child, childPointer)...)
+			}
+		}
+		if !matched && s.additional != nil {
+			if s.additional.boolean != nil && !*s.additional.boolean {
+				errs = append(errs, failure(child, childPointer, fmt.Sprintf("property %q is not allowed", key)))
+			} else {
+				errs = append(errs, s.additional.validate(child, childPointer)...)
+			}
+		}
+	}
+	return errs
+}
+
+// validateArray applies the array keywords to a list node
+func (s *Schema) validateArray(node *tree.Node, pointer string) ValidationErrors {
+	var errs ValidationErrors
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, failure(node, pointer, fmt.Sprintf(format, args...)))
+	}
+
+	if s.minItems != nil && len(node.Items) < *s.minItems {
+		fail("must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(node.Items) > *s.maxItems {
+		fail("must have at most %d items", *s.maxItems)
+	}
+
+	matched := 0
+	var seen []interface{}
+	for i, item := range node.Items {
+		itemPointer := pointer + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(s.prefixItems):
+			errs = append(errs, s.prefixItems[i].validate(item, itemPointer)...)
+		case s.items != nil:
+			errs = append(errs, s.items.validate(item, itemPointer)...)
+		}
+		if s.contains != nil && len(s.contains.validate(item, itemPointer)) == 0 {
+			matched++
+		}
+		if s.uniqueItems {
+			value := normalize(item.Interface())
+			if contains(seen, value) {
+				errs = append(errs, failure(item, itemPointer, "duplicates an earlier item"))
+			}
+			seen = append(seen, value)
+		}
+	}
+	if s.contains != nil && matched == 0 {
+		fail("must contain at least one matching item")
+	}
+	return errs
+}
+
+// failure creates a ValidationError for a node
+func failure(node *tree.Node, pointer, message string) *ValidationError {
+	return &ValidationError{Pointer: pointer, Pos: node.Pos, Message: message}
+}
+
+// kindOf returns the JSON type of a node. Timestamps decoded by YAML are strings, and numbers are integers
+// when they have no fractional part
+func kindOf(node *tree.Node) string {
+	switch node.Kind {
+	case tree.NullNode:
+		return "null"
+	case tree.MapNode:
+		return "object"
+	case tree.ListNode:
+		return "array"
+	}
+
+	switch node.Value.(type) {
+	case bool:
+		return "boolean"
+	case string, time.Time:
+		return "string"
+	}
+	if value, ok := numberValue(node.Value); ok {
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "string"
+}
+
+// matchesType reports whether a value of the given JSON type satisfies the type keyword
+func matchesType(types []string, kind string) bool {
+	for _, name := range types {
+		if name == kind || (name == "number" && kind == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue returns the text of a string scalar
+func stringValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true
+	}
+	return "", false
+}
+
+// numberValue returns a numeric scalar as a float64
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// normalize converts decoded values into a canonical form for const, enum and uniqueItems, so that 1,
+// int64(1) and 1.0 compare equal
+func normalize(value interface{}) interface{} {
+	if number, ok := numberValue(value); ok {
+		return number
+	}
+	if text, ok := stringValue(value); ok {
+		return text
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[key] = normalize(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			result = append(result, normalize(item))
+		}
+		return result
+	}
+	return value
+}
+
+// contains reports whether values holds value
+func contains(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// format renders a schema value as JSON for messages, with the keys of objects sorted
+func format(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
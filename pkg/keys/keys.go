@@ -0,0 +1,79 @@
+package keys
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalizer converts the path of a configuration key, one segment per nesting level, into the flat key
+// used for lookups. Flat keys such as an env tag are passed as a single segment
+type Normalizer interface {
+	Normalize(path ...string) string
+}
+
+// Func adapts an ordinary function to the Normalizer interface
+type Func func(path ...string) string
+
+// Normalize calls f(path...)
+func (f Func) Normalize(path ...string) string {
+	return f(path...)
+}
+
+var (
+	// Upper uppercases every segment and joins them with '_', so db.maxConns becomes DB_MAXCONNS. This is
+	// what the structured loaders do when no normalizer is configured
+	Upper Normalizer = Func(func(path ...string) string {
+		return strings.ToUpper(strings.Join(path, "_"))
+	})
+
+	// UpperSnake splits segments at camelCase boundaries, dots, dashes and spaces, so db.maxConns and
+	// db-max-conns both become DB_MAX_CONNS
+	UpperSnake Normalizer = Func(func(path ...string) string {
+		return strings.ToUpper(strings.Join(splitWords(path, true), "_"))
+	})
+
+	// DottedLower lowercases every segment and joins words with '.', so DB_MAX_CONNS becomes db.max.conns
+	DottedLower Normalizer = Func(func(path ...string) string {
+		return strings.ToLower(strings.Join(splitWords(path, false), "."))
+	})
+
+	// AsIs keeps keys unchanged and joins nested segments with '.'
+	AsIs Normalizer = Func(func(path ...string) string {
+		return strings.Join(path, ".")
+	})
+)
+
+// splitWords splits every segment at '_', '.', '-' and whitespace and, if camelCase is set, at lower to
+// upper case transitions and at the end of an acronym (HTTPServer becomes HTTP, Server)
+func splitWords(path []string, camelCase bool) []string {
+	var words []string
+	for _, segment := range path {
+		runes := []rune(segment)
+		start := -1
+		for i, r := range runes {
+			if r == '_' || r == '.' || r == '-' || unicode.IsSpace(r) {
+				if start != -1 {
+					words = append(words, string(runes[start:i]))
+					start = -1
+				}
+				continue
+			}
+			if start == -1 {
+				start = i
+				continue
+			}
+			if camelCase && unicode.IsUpper(r) {
+				previous := runes[i-1]
+				nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+					words = append(words, string(runes[start:i]))
+					start = i
+				}
+			}
+		}
+		if start != -1 {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
@@ -0,0 +1,43 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizers(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer Normalizer
+		path       []string
+		expected   string
+	}{
+		{name: "Upper nested", normalizer: Upper, path: []string{"db", "maxConns"}, expected: "DB_MAXCONNS"},
+		{name: "Upper flat", normalizer: Upper, path: []string{"DB_URL"}, expected: "DB_URL"},
+		{name: "UpperSnake camelCase", normalizer: UpperSnake, path: []string{"db", "maxConns"}, expected: "DB_MAX_CONNS"},
+		{name: "UpperSnake dotted", normalizer: UpperSnake, path: []string{"db.maxConns"}, expected: "DB_MAX_CONNS"},
+		{name: "UpperSnake dashes", normalizer: UpperSnake, path: []string{"db-max-conns"}, expected: "DB_MAX_CONNS"},
+		{name: "UpperSnake acronym", normalizer: UpperSnake, path: []string{"HTTPServer", "v2Api"}, expected: "HTTP_SERVER_V2_API"},
+		{name: "UpperSnake already normalized", normalizer: UpperSnake, path: []string{"DB_MAX_CONNS"}, expected: "DB_MAX_CONNS"},
+		{name: "UpperSnake list index", normalizer: UpperSnake, path: []string{"hosts", "0"}, expected: "HOSTS_0"},
+		{name: "DottedLower nested", normalizer: DottedLower, path: []string{"DB", "Max_Conns"}, expected: "db.max.conns"},
+		{name: "DottedLower flat", normalizer: DottedLower, path: []string{"DB_URL"}, expected: "db.url"},
+		{name: "AsIs nested", normalizer: AsIs, path: []string{"db", "maxConns"}, expected: "db.maxConns"},
+		{name: "AsIs flat", normalizer: AsIs, path: []string{"DB_URL"}, expected: "DB_URL"},
+		{
+			name: "Custom func",
+			normalizer: Func(func(path ...string) string {
+				return "APP_" + Upper.Normalize(path...)
+			}),
+			path:     []string{"db", "url"},
+			expected: "APP_DB_URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.normalizer.Normalize(tt.path...), "normalized key mismatch")
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package tree holds configuration documents as trees that keep their structure, value types and source
+// positions. Loaders implementing TreeLoader decode into it, writers encode from it and JSON Schemas
+// validate it, so custom formats can take part in all three
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind identifies the type of a Node
+type Kind int
+
+const (
+	// NullNode is an explicit null
+	NullNode Kind = iota
+	// ScalarNode holds a typed scalar such as a string, bool, int64, uint64, float64 or time.Time
+	ScalarNode
+	// MapNode holds named children in document order
+	MapNode
+	// ListNode holds ordered items
+	ListNode
+)
+
+// Position is the location of a node in its source file. Line and Column start at 1 and are 0 when unknown
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String formats the position as file:line:column, leaving out unknown parts
+func (p Position) String() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+}
+
+// Node is a configuration document kept as a tree, preserving structure, value types and source positions
+type Node struct {
+	Kind     Kind
+	Value    interface{}
+	Keys     []string
+	Children map[string]*Node
+	Items    []*Node
+	Pos      Position
+}
+
+// NewMap creates an empty map node
+func NewMap(pos Position) *Node {
+	return &Node{Kind: MapNode, Children: make(map[string]*Node), Pos: pos}
+}
+
+// NewList creates an empty list node
+func NewList(pos Position) *Node {
+	return &Node{Kind: ListNode, Pos: pos}
+}
+
+// NewScalar creates a scalar node, or a null node if value is nil
+func NewScalar(value interface{}, pos Position) *Node {
+	if value == nil {
+		return &Node{Kind: NullNode, Pos: pos}
+	}
+	return &Node{Kind: ScalarNode, Value: value, Pos: pos}
+}
+
+// Set adds or replaces a child of a map node, keeping the position of the first occurrence in Keys
+func (n *Node) Set(key string, child *Node) {
+	if _, exists := n.Children[key]; !exists {
+		n.Keys = append(n.Keys, key)
+	}
+	n.Children[key] = child
+}
+
+// Delete removes a child of a map node
+func (n *Node) Delete(key string) {
+	if _, exists := n.Children[key]; !exists {
+		return
+	}
+	delete(n.Children, key)
+	for i, existing := range n.Keys {
+		if existing == key {
+			n.Keys = append(n.Keys[:i], n.Keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// String formats a scalar node the way it appears in the flat view
+func (n *Node) String() string {
+	scalar, _ := FormatScalar(n.Value)
+	return scalar
+}
+
+// Joined returns the comma-joined form of a list of scalars, as it appears in the flat view. It reports
+// false for other nodes and for lists that contain maps or lists
+func (n *Node) Joined() (string, bool) {
+	if n.Kind != ListNode {
+		return "", false
+	}
+	items := make([]string, 0, len(n.Items))
+	for _, item := range n.Items {
+		if item.Kind == MapNode || item.Kind == ListNode {
+			return "", false
+		}
+		items = append(items, item.String())
+	}
+	return strings.Join(items, ","), true
+}
+
+// Interface converts the tree back into plain maps, slices and scalars
+func (n *Node) Interface() interface{} {
+	switch n.Kind {
+	case MapNode:
+		result := make(map[string]interface{}, len(n.Children))
+		for key, child := range n.Children {
+			result[key] = child.Interface()
+		}
+		return result
+	case ListNode:
+		result := make([]interface{}, 0, len(n.Items))
+		for _, item := range n.Items {
+			result = append(result, item.Interface())
+		}
+		return result
+	default:
+		return n.Value
+	}
+}
+
+// FromValue builds a tree without positions from decoded maps, slices and scalars
+func FromValue(value interface{}) (*Node, error) {
+	return fromValue(value, nil, Position{})
+}
+
+// FromValueAt builds a tree from decoded values, giving every node the position pos
+func FromValueAt(value interface{}, pos Position) (*Node, error) {
+	return fromValue(value, nil, pos)
+}
+
+func fromValue(value interface{}, path []string, pos Position) (*Node, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		node := NewMap(pos)
+		for key, child := range v {
+			childNode, err := fromValue(child, append(path, key), pos)
+			if err != nil {
+				return nil, err
+			}
+			node.Set(key, childNode)
+		}
+		return node, nil
+	case map[interface{}]interface{}:
+		node := NewMap(pos)
+		for key, child := range v {
+			childNode, err := fromValue(child, append(path, fmt.Sprint(key)), pos)
+			if err != nil {
+				return nil, err
+			}
+			node.Set(fmt.Sprint(key), childNode)
+		}
+		return node, nil
+	case []interface{}:
+		node := NewList(pos)
+		for i, item := range v {
+			itemNode, err := fromValue(item, append(path, strconv.Itoa(i)), pos)
+			if err != nil {
+				return nil, err
+			}
+			node.Items = append(node.Items, itemNode)
+		}
+		return node, nil
+	default:
+		if _, ok := FormatScalar(value); !ok {
+			return nil, fmt.Errorf("unsupported value type %T for key %s", value, strings.Join(path, "."))
+		}
+		return NewScalar(value, pos), nil
+	}
+}
+
+// Merge merges src into dst and returns the result. Maps are merged key by key; any other value in src
+// replaces the value in dst. dst is modified in place when both are maps
+func Merge(dst, src *Node) *Node {
+	if dst == nil || dst.Kind != MapNode || src.Kind != MapNode {
+		return src
+	}
+	for _, key := range src.Keys {
+		dst.Set(key, Merge(dst.Children[key], src.Children[key]))
+	}
+	return dst
+}
+
+// FormatScalar formats a decoded scalar value as a string. Nil formats as an empty string; maps, arrays and
+// unknown types are not scalars
+func FormatScalar(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true
+	default:
+		return "", false
+	}
+}
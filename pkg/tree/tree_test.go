@@ -0,0 +1,24 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPosition_String(t *testing.T) {
+	assert.Equal(t, "a.yaml", Position{File: "a.yaml"}.String())
+	assert.Equal(t, "a.yaml:3", Position{File: "a.yaml", Line: 3}.String())
+	assert.Equal(t, "a.yaml:3:7", Position{File: "a.yaml", Line: 3, Column: 7}.String())
+}
+
+func TestMerge(t *testing.T) {
+	dst, _ := FromValue(map[string]interface{}{"db": map[string]interface{}{"host": "a", "port": 1}, "tags": []interface{}{"x"}})
+	src, _ := FromValue(map[string]interface{}{"db": map[string]interface{}{"port": 2}, "tags": []interface{}{"y", "z"}})
+
+	result := Merge(dst, src)
+	assert.Equal(t, map[string]interface{}{
+		"db":   map[string]interface{}{"host": "a", "port": 2},
+		"tags": []interface{}{"y", "z"},
+	}, result.Interface(), "maps should be merged and lists replaced")
+}